@@ -0,0 +1,283 @@
+package gobe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Actions of the audit log
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// A change of a record written by a repository with Audit on, in the audit_logs table.
+// Before and After hold the columns of the record as JSON, Diff the columns that changed with their old and new values
+type AuditLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Table     string    `gorm:"column:table_name;size:128;index:idx_audit_logs_record" json:"table"`
+	RecordID  string    `gorm:"size:191;index:idx_audit_logs_record" json:"record_id"`
+	Model     string    `gorm:"size:128" json:"model"`
+	Action    string    `gorm:"size:16" json:"action"`
+	Actor     string    `gorm:"size:191;index" json:"actor"`
+	Before    string    `json:"before,omitempty"`
+	After     string    `json:"after,omitempty"`
+	Diff      string    `json:"diff"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// Old and new value of a column in the diff of an AuditLog, the old value of a created record and the new value of a removed one are null
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Decode the diff of the entry
+func (l AuditLog) Changes() (map[string]AuditChange, error) {
+	var changes map[string]AuditChange
+	err := json.Unmarshal([]byte(l.Diff), &changes)
+	return changes, err
+}
+
+type actorKey struct{}
+
+// Set who makes the changes of the repositories called with the context, e.g. the ID of the authenticated user
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Get the actor set by WithActor, empty when there is none
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// Set the actor of the request context, so the repositories called with c.Request.Context() record it, e.g. in an authentication middleware
+//
+//	Example:
+//	SetAuditActor(c, claims.Subject)
+//	c.Next()
+func SetAuditActor(c *gin.Context, actor string) {
+	c.Request = c.Request.WithContext(WithActor(c.Request.Context(), actor))
+}
+
+// A record read for the audit log, its primary key and its columns
+type auditRecord struct {
+	key    string
+	pk     []interface{}
+	values map[string]interface{}
+}
+
+// Run a write of the records of model, recorded in the audit log in the same transaction when audit is on.
+// The records the write changes are the ones matching the conditions and the primary key of model, or the records of model for a create
+func auditedWrite(ctx context.Context, db *gorm.DB, audit bool, model interface{}, by map[string]interface{}, action string, write func(db *gorm.DB) error) error {
+	if !audit {
+		return write(connection(ctx, db))
+	}
+	return RunInTx(ctx, db, func(ctx context.Context) error {
+		tx := connection(ctx, db)
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		s := stmt.Schema
+		if len(s.PrimaryFields) == 0 {
+			return fmt.Errorf("%s has no primary key, its changes cannot be audited", s.Name)
+		}
+
+		var before, after []auditRecord
+		var err error
+		if action != AuditCreate {
+			query := tx.Unscoped().Where(by)
+			if item := reflect.Indirect(reflect.ValueOf(model)); item.Kind() == reflect.Struct {
+				for _, field := range s.PrimaryFields {
+					if value, zero := field.ValueOf(ctx, item); !zero {
+						query = query.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: value})
+					}
+				}
+			}
+			if before, err = findAuditRecords(ctx, query, s); err != nil {
+				return err
+			}
+		}
+		if err := write(tx); err != nil {
+			return err
+		}
+		if action == AuditCreate {
+			after = auditRecordsOf(ctx, s, reflect.Indirect(reflect.ValueOf(model)))
+		} else if after, err = findAuditRecordsByKey(ctx, tx, s, before); err != nil {
+			return err
+		}
+
+		entries, err := auditEntries(s, ActorFromContext(ctx), action, before, after)
+		if err != nil || len(entries) == 0 {
+			return err
+		}
+		return tx.Create(&entries).Error
+	})
+}
+
+func findAuditRecords(ctx context.Context, query *gorm.DB, s *schema.Schema) ([]auditRecord, error) {
+	dest := reflect.New(reflect.SliceOf(s.ModelType))
+	if err := query.Find(dest.Interface()).Error; err != nil {
+		return nil, err
+	}
+	return auditRecordsOf(ctx, s, dest.Elem()), nil
+}
+
+// Read the records again after the write, a removed record is missing
+func findAuditRecordsByKey(ctx context.Context, db *gorm.DB, s *schema.Schema, records []auditRecord) ([]auditRecord, error) {
+	var res []auditRecord
+	chunk := maxKeyParams / len(s.PrimaryFields)
+	for start := 0; start < len(records); start += chunk {
+		end := start + chunk
+		if end > len(records) {
+			end = len(records)
+		}
+		matches := make([]Filter, 0, end-start)
+		for _, record := range records[start:end] {
+			conditions := make([]Filter, len(s.PrimaryFields))
+			for i, field := range s.PrimaryFields {
+				conditions[i] = Eq(field.DBName, record.pk[i])
+			}
+			matches = append(matches, And(conditions...))
+		}
+		found, err := findAuditRecords(ctx, Or(matches...).apply(db.Unscoped()), s)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, found...)
+	}
+	return res, nil
+}
+
+// Get the columns of a record or of every record of a slice
+func auditRecordsOf(ctx context.Context, s *schema.Schema, value reflect.Value) []auditRecord {
+	items := []reflect.Value{value}
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		items = make([]reflect.Value, value.Len())
+		for i := range items {
+			items[i] = reflect.Indirect(value.Index(i))
+		}
+	}
+	records := make([]auditRecord, 0, len(items))
+	for _, item := range items {
+		record := auditRecord{values: map[string]interface{}{}}
+		for _, field := range s.Fields {
+			if field.DBName != "" {
+				record.values[field.DBName], _ = field.ValueOf(ctx, item)
+			}
+		}
+		for _, field := range s.PrimaryFields {
+			value, _ := field.ValueOf(ctx, item)
+			record.pk = append(record.pk, value)
+		}
+		record.key = auditRecordID(record.pk)
+		records = append(records, record)
+	}
+	return records
+}
+
+// The record ID of the audit log, the values of the primary key separated by commas
+func auditRecordID(pk []interface{}) string {
+	keys := make([]string, len(pk))
+	for i, value := range pk {
+		keys[i] = fmt.Sprint(value)
+	}
+	return strings.Join(keys, ",")
+}
+
+// Make an entry per changed record, the records left unchanged by the write are skipped
+func auditEntries(s *schema.Schema, actor, action string, before, after []auditRecord) ([]AuditLog, error) {
+	afterByKey := make(map[string]auditRecord, len(after))
+	for _, record := range after {
+		afterByKey[record.key] = record
+	}
+	var keys []string
+	beforeByKey := make(map[string]auditRecord, len(before))
+	for _, record := range before {
+		beforeByKey[record.key] = record
+		keys = append(keys, record.key)
+	}
+	if action == AuditCreate {
+		for _, record := range after {
+			keys = append(keys, record.key)
+		}
+	}
+
+	var entries []AuditLog
+	for _, key := range keys {
+		oldValues, newValues := beforeByKey[key].values, afterByKey[key].values
+		diff := map[string]AuditChange{}
+		for _, field := range s.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			oldJSON, err := json.Marshal(oldValues[field.DBName])
+			if err != nil {
+				return nil, err
+			}
+			newJSON, err := json.Marshal(newValues[field.DBName])
+			if err != nil {
+				return nil, err
+			}
+			if (oldValues != nil) != (newValues != nil) || !bytes.Equal(oldJSON, newJSON) {
+				diff[field.DBName] = AuditChange{Old: oldValues[field.DBName], New: newValues[field.DBName]}
+			}
+		}
+		if len(diff) == 0 {
+			continue
+		}
+		entry := AuditLog{Table: s.Table, RecordID: key, Model: s.Name, Action: action, Actor: actor}
+		var err error
+		if entry.Before, err = auditJSON(oldValues); err != nil {
+			return nil, err
+		}
+		if entry.After, err = auditJSON(newValues); err != nil {
+			return nil, err
+		}
+		if entry.Diff, err = auditJSON(diff); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func auditJSON(value interface{}) (string, error) {
+	if v := reflect.ValueOf(value); !v.IsValid() || v.IsNil() {
+		return "", nil
+	}
+	res, err := json.Marshal(value)
+	return string(res), err
+}
+
+// Find the audit log entries of a record, the oldest first. id is the primary key, or a slice of its values for a composite key
+func auditHistory(db *gorm.DB, model interface{}, id interface{}) ([]AuditLog, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	pk := []interface{}{id}
+	if v := reflect.ValueOf(id); (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8 {
+		pk = make([]interface{}, v.Len())
+		for i := range pk {
+			pk[i] = v.Index(i).Interface()
+		}
+	}
+	var logs []AuditLog
+	err := db.Where(map[string]interface{}{"table_name": stmt.Schema.Table, "record_id": auditRecordID(pk)}).
+		Order("created_at, id").Find(&logs).Error
+	return logs, err
+}
@@ -0,0 +1,81 @@
+package gobe
+
+import (
+	"context"
+	"testing"
+)
+
+type auditItem struct {
+	ID    uint
+	Name  string
+	Price int
+}
+
+func TestAuditHistory(t *testing.T) {
+	db := newTestDB(t, &auditItem{}, &AuditLog{})
+	repo := &GormRepository{Db: db, Audit: true}
+	ctx := WithActor(context.Background(), "user-1")
+
+	item := auditItem{Name: "a", Price: 1}
+	if err := repo.CreateContext(ctx, &item); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateByContext(ctx, &auditItem{}, map[string]interface{}{"id": item.ID}, map[string]interface{}{"price": 2}); err != nil {
+		t.Fatal(err)
+	}
+	// Nothing changes, so nothing is recorded
+	if err := repo.UpdateByContext(ctx, &auditItem{}, map[string]interface{}{"id": item.ID}, map[string]interface{}{"price": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteByContext(WithActor(context.Background(), "admin"), &auditItem{}, map[string]interface{}{"id": item.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	logs, err := repo.History(&auditItem{}, item.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		action string
+		actor  string
+		column string
+		old    interface{}
+		new    interface{}
+	}{
+		{AuditCreate, "user-1", "price", nil, float64(1)},
+		{AuditUpdate, "user-1", "price", float64(1), float64(2)},
+		{AuditDelete, "admin", "name", "a", nil},
+	}
+	if len(logs) != len(tests) {
+		t.Fatalf("got %d entries, want %d: %+v", len(logs), len(tests), logs)
+	}
+	for i, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			log := logs[i]
+			if log.Action != tt.action || log.Actor != tt.actor || log.Table != "audit_items" {
+				t.Errorf("got %s by %s on %s, want %s by %s on audit_items", log.Action, log.Actor, log.Table, tt.action, tt.actor)
+			}
+			changes, err := log.Changes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if change := changes[tt.column]; change.Old != tt.old || change.New != tt.new {
+				t.Errorf("%s changed from %v to %v, want from %v to %v", tt.column, change.Old, change.New, tt.old, tt.new)
+			}
+		})
+	}
+}
+
+func TestAuditRolledBackWithTheWrite(t *testing.T) {
+	db := newTestDB(t, &auditItem{}, &AuditLog{})
+	repo := &GormRepository{Db: db, Audit: true}
+	RunInTx(context.Background(), db, func(ctx context.Context) error {
+		repo.CreateContext(ctx, &auditItem{Name: "a"})
+		return context.Canceled
+	})
+	var count int64
+	db.Model(&AuditLog{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d audit entries, want them rolled back with the write", count)
+	}
+}
@@ -0,0 +1,208 @@
+package gobe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Number of records inserted per statement when the batch size is not set
+const defaultBatchSize = 1000
+
+// Upper bound of the parameters of a query matching records by their keys, e.g. counting the existing records of an upsert
+const maxKeyParams = 1000
+
+// Number of records an upsert inserted and updated. A key repeated in the input is counted once.
+//
+// MySQL doesn't report the records already holding the values, so Updated only counts the records that changed
+type UpsertResult struct {
+	Inserted int64 `json:"inserted"`
+	Updated  int64 `json:"updated"`
+}
+
+// Insert the records of a pointer to a slice, batchSize records per statement, in a transaction. batchSize defaults to 1000.
+// Returns the number of inserted records
+func createInBatches(ctx context.Context, db *gorm.DB, model interface{}, batchSize int) (int64, error) {
+	if err := checkGeneratedKey(db, model); err != nil {
+		return 0, err
+	}
+	if batchSize < 1 {
+		batchSize = defaultBatchSize
+	}
+	res := connection(ctx, db).CreateInBatches(model, batchSize)
+	return res.RowsAffected, res.Error
+}
+
+// Insert the records of model, a pointer to a struct or a slice, updating the ones conflicting on the conflict columns instead.
+// The conflict columns default to the primary key, and every column but the primary key is updated when updateColumns is empty.
+//
+// The records matching the conflict columns are counted first in the same transaction, so the counts don't depend on how the driver reports affected rows.
+// A key repeated in the input is written once with its last item, a single statement cannot update a record twice on PostgreSQL,
+// and the earlier items of the key are replaced by the last one.
+func upsert(ctx context.Context, db *gorm.DB, model interface{}, conflictColumns, updateColumns []string) (UpsertResult, error) {
+	if err := requireFeature(db, FeatureUpsert); err != nil {
+		return UpsertResult{}, err
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return UpsertResult{}, err
+	}
+	if len(conflictColumns) == 0 {
+		conflictColumns = stmt.Schema.PrimaryFieldDBNames
+	}
+	conflictFields := make([]*schema.Field, len(conflictColumns))
+	onConflict := clause.OnConflict{Columns: make([]clause.Column, len(conflictColumns))}
+	for i, column := range conflictColumns {
+		field, err := lookUpColumn(stmt.Schema, column)
+		if err != nil {
+			return UpsertResult{}, err
+		}
+		conflictFields[i] = field
+		onConflict.Columns[i] = clause.Column{Name: field.DBName}
+	}
+	if len(updateColumns) == 0 {
+		onConflict.UpdateAll = true
+	} else {
+		names := make([]string, len(updateColumns))
+		for i, column := range updateColumns {
+			field, err := lookUpColumn(stmt.Schema, column)
+			if err != nil {
+				return UpsertResult{}, err
+			}
+			names[i] = field.DBName
+		}
+		onConflict.DoUpdates = clause.AssignmentColumns(names)
+	}
+
+	items := reflect.Indirect(reflect.ValueOf(model))
+	if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+		items = reflect.Append(reflect.MakeSlice(reflect.SliceOf(items.Type()), 0, 1), items)
+	}
+	keys, keyOf := distinctKeys(db.Statement.Context, conflictFields, items)
+	rows := model
+	var unique reflect.Value
+	if len(keys) < items.Len() {
+		unique = reflect.New(reflect.SliceOf(items.Type().Elem()))
+		unique.Elem().Set(reflect.MakeSlice(unique.Elem().Type(), len(keys), len(keys)))
+		for i := 0; i < items.Len(); i++ {
+			unique.Elem().Index(keyOf[i]).Set(items.Index(i))
+		}
+		rows = unique.Interface()
+	}
+
+	var res UpsertResult
+	err := RunInTx(ctx, db, func(ctx context.Context) error {
+		existing, err := countExisting(connection(ctx, db), model, conflictFields, keys)
+		if err != nil {
+			return err
+		}
+		write := connection(ctx, db).Clauses(onConflict).CreateInBatches(rows, defaultBatchSize)
+		if write.Error != nil {
+			return write.Error
+		}
+		res = UpsertResult{Inserted: int64(len(keys)) - existing, Updated: existing}
+		if gormDriver(db) == Mysql {
+			res.Updated = mysqlUpdatedRows(int64(len(keys)), res.Inserted, write.RowsAffected, mysqlFoundRows(db))
+		}
+		return nil
+	})
+	if err != nil {
+		return UpsertResult{}, err
+	}
+	// Give the generated fields of the records back to every item of their key
+	if unique.IsValid() && items.CanSet() {
+		for i := 0; i < items.Len(); i++ {
+			items.Index(i).Set(unique.Elem().Index(keyOf[i]))
+		}
+	}
+	return res, nil
+}
+
+// Get the values of the fields of every distinct key, and the position of the key of every item
+func distinctKeys(ctx context.Context, fields []*schema.Field, items reflect.Value) ([][]interface{}, []int) {
+	positions := make(map[string]int, items.Len())
+	keys := make([][]interface{}, 0, items.Len())
+	keyOf := make([]int, items.Len())
+	for i := 0; i < items.Len(); i++ {
+		item := reflect.Indirect(items.Index(i))
+		key := make([]interface{}, len(fields))
+		values := make([]interface{}, len(fields))
+		for j, field := range fields {
+			key[j], _ = field.ValueOf(ctx, item)
+			// Compare the values behind pointers, not their addresses
+			values[j] = reflect.Indirect(reflect.ValueOf(key[j])).Interface()
+		}
+		id := fmt.Sprintf("%#v", values)
+		position, ok := positions[id]
+		if !ok {
+			position = len(keys)
+			positions[id] = position
+			keys = append(keys, key)
+		}
+		keyOf[i] = position
+	}
+	return keys, keyOf
+}
+
+// Get the number of records an ON DUPLICATE KEY UPDATE changed from its affected rows: 1 per inserted record, 2 per updated one,
+// and 0 per record already holding the values, or 1 when the connection sets CLIENT_FOUND_ROWS (clientFoundRows=true)
+func mysqlUpdatedRows(records, inserted, affected int64, foundRows bool) int64 {
+	var updated int64
+	if foundRows {
+		updated = affected - records
+	} else {
+		updated = (affected - inserted) / 2
+	}
+	if updated < 0 {
+		return 0
+	}
+	return updated
+}
+
+// Tell whether the MySQL connection counts the matched rows instead of the changed ones
+func mysqlFoundRows(db *gorm.DB) bool {
+	dialector, ok := db.Dialector.(*mysql.Dialector)
+	if !ok || dialector.Config == nil || dialector.DSN == "" {
+		return false
+	}
+	cfg, err := mysqldriver.ParseDSN(dialector.DSN)
+	return err == nil && cfg.ClientFoundRows
+}
+
+// Count the records having the same values of the fields as one of the keys, soft deleted ones included
+func countExisting(db *gorm.DB, model interface{}, fields []*schema.Field, keys [][]interface{}) (int64, error) {
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	chunk := maxKeyParams / len(fields)
+	if chunk < 1 {
+		chunk = 1
+	}
+	var total int64
+	for start := 0; start < len(keys); start += chunk {
+		end := start + chunk
+		if end > len(keys) {
+			end = len(keys)
+		}
+		matches := make([]Filter, 0, end-start)
+		for _, key := range keys[start:end] {
+			conditions := make([]Filter, len(fields))
+			for j, field := range fields {
+				conditions[j] = Eq(field.DBName, key[j])
+			}
+			matches = append(matches, And(conditions...))
+		}
+		var count int64
+		if err := Or(matches...).apply(db.Model(model).Unscoped()).Count(&count).Error; err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
@@ -0,0 +1,152 @@
+package gobe
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type bulkItem struct {
+	ID   uint
+	SKU  string `gorm:"uniqueIndex"`
+	Name string
+}
+
+func TestUpsertCounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []bulkItem
+		inserted int64
+		updated  int64
+		rows     int64
+	}{
+		{"insert only", []bulkItem{{SKU: "c", Name: "C"}, {SKU: "d", Name: "D"}}, 2, 0, 4},
+		{"update only", []bulkItem{{SKU: "a", Name: "A2"}}, 0, 1, 2},
+		{"insert and update", []bulkItem{{SKU: "a", Name: "A2"}, {SKU: "c", Name: "C"}}, 1, 1, 3},
+		{"repeated existing key", []bulkItem{{SKU: "a", Name: "A2"}, {SKU: "a", Name: "A3"}}, 0, 1, 2},
+		{"repeated new key", []bulkItem{{SKU: "c", Name: "C"}, {SKU: "c", Name: "C2"}, {SKU: "b", Name: "B2"}}, 1, 1, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &bulkItem{})
+			if err := db.Create(&[]bulkItem{{SKU: "a", Name: "A"}, {SKU: "b", Name: "B"}}).Error; err != nil {
+				t.Fatal(err)
+			}
+			repo := Repository[bulkItem]{Db: db}
+			res, err := repo.Upsert(context.Background(), tt.items, []string{"sku"}, []string{"name"})
+			if err != nil {
+				t.Fatalf("upsert: %v", err)
+			}
+			if res.Inserted != tt.inserted || res.Updated != tt.updated {
+				t.Errorf("got %+v, want %d inserted and %d updated", res, tt.inserted, tt.updated)
+			}
+			var rows int64
+			db.Model(&bulkItem{}).Count(&rows)
+			if rows != tt.rows {
+				t.Errorf("%d rows, want %d", rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestUpsertRepeatedKeys(t *testing.T) {
+	db := newTestDB(t, &bulkItem{})
+	db.Create(&bulkItem{SKU: "a", Name: "A"})
+	repo := &GormRepository{Db: db}
+	items := []bulkItem{{SKU: "c", Name: "C"}, {SKU: "a", Name: "A2"}, {SKU: "c", Name: "C2"}}
+
+	// The statement has one row per key, so PostgreSQL doesn't update a record twice
+	var inserts []int
+	db.Callback().Create().Before("gorm:create").Register("test:rows", func(db *gorm.DB) {
+		inserts = append(inserts, db.Statement.ReflectValue.Len())
+	})
+	defer db.Callback().Create().Remove("test:rows")
+
+	res, err := repo.Upsert(&items, []string{"sku"}, []string{"name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Inserted != 1 || res.Updated != 1 {
+		t.Errorf("got %+v, want 1 inserted and 1 updated", res)
+	}
+	if len(inserts) != 1 || inserts[0] != 2 {
+		t.Errorf("inserted rows per statement %v, want [2]", inserts)
+	}
+	if items[0] != items[2] || items[0].Name != "C2" || items[0].ID == 0 {
+		t.Errorf("items %+v, want the items of the repeated key to hold the stored record", items)
+	}
+}
+
+func TestMysqlUpdatedRows(t *testing.T) {
+	tests := []struct {
+		name      string
+		records   int64
+		inserted  int64
+		affected  int64
+		foundRows bool
+		want      int64
+	}{
+		{"inserts only", 3, 3, 3, false, 0},
+		{"updates only", 2, 0, 4, false, 2},
+		{"inserts and updates", 3, 1, 5, false, 2},
+		{"unchanged records", 3, 1, 3, false, 1},
+		{"nothing changed", 2, 0, 0, false, 0},
+		{"found rows with unchanged records", 3, 1, 4, true, 1},
+		{"found rows with updates only", 2, 0, 4, true, 2},
+		{"other unique index", 2, 2, 1, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mysqlUpdatedRows(tt.records, tt.inserted, tt.affected, tt.foundRows); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMysqlFoundRows(t *testing.T) {
+	tests := []struct {
+		dsn  string
+		want bool
+	}{
+		{"user:pass@tcp(localhost:3306)/app", false},
+		{"user:pass@tcp(localhost:3306)/app?clientFoundRows=true", true},
+		{"", false},
+	}
+	for _, tt := range tests {
+		db := &gorm.DB{Config: &gorm.Config{Dialector: mysql.New(mysql.Config{DSN: tt.dsn})}}
+		if got := mysqlFoundRows(db); got != tt.want {
+			t.Errorf("%q: got %t, want %t", tt.dsn, got, tt.want)
+		}
+	}
+}
+
+func TestCreateInBatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     int
+		batchSize int
+	}{
+		{"default batch size", 5, 0},
+		{"several batches", 7, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &bulkItem{})
+			items := make([]bulkItem, tt.count)
+			for i := range items {
+				items[i] = bulkItem{SKU: string(rune('a' + i))}
+			}
+			repo := Repository[bulkItem]{Db: db}
+			inserted, err := repo.CreateInBatches(context.Background(), items, tt.batchSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if inserted != int64(tt.count) {
+				t.Errorf("inserted %d, want %d", inserted, tt.count)
+			}
+		})
+	}
+}
@@ -1,11 +1,24 @@
 package gobe
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
 	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
 
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// Default prefix of the environment variables read by ConfigLoader
+const DefaultEnvPrefix = "GOBE"
+
 type Config struct {
 	ApiConfig   RestApiBaseConfig `mapstructure:"restapi" json:"restapi"`
 	SqlConfig   SqlBaseConfig     `mapstructure:"sql" json:"sql"`
@@ -15,17 +28,252 @@ type Config struct {
 	// GrpcConfig GrpcBaseConfig `mapstructure:"grpc" json:"grpc"`
 }
 
+// ConfigLoader merges the configuration file, environment variables and command-line flags into a Config.
+//
+// Every key is named after the mapstructure tags, e.g. "sql.db_password". The precedence from highest to lowest is:
+//  1. command-line flag explicitly set, e.g. --sql.db_password=xxx
+//  2. environment variable, e.g. GOBE_SQL_DB_PASSWORD=xxx
+//  3. profile overlay file, e.g. config.staging.json
+//  4. base configuration file, e.g. config.json
+//
+// Secret fields holding a reference such as "file:///run/secrets/db_pass" are resolved once everything is merged.
+//
+// Each call to Load uses its own viper instance, so several configs can be loaded side by side.
+type ConfigLoader struct {
+	// Prefix of the environment variables, DefaultEnvPrefix is used when empty
+	EnvPrefix string
+	// Flags registered by RegisterConfigFlags. The flag overrides are disabled when nil
+	Flags *pflag.FlagSet
+	// Resolvers of secret references by scheme, used on top of the built-in "file" and "env" ones
+	SecretResolvers map[string]SecretResolver
+	// Name of the profile (e.g. "staging"). When set, the overlay file next to the base file is deep-merged into it,
+	// e.g. config.staging.json for config.json
+	Profile string
+}
+
+// ConfigSources tells where each effective configuration value came from, keyed by its path (e.g. "sql.db_port").
+// The source is either a file path, "env:<VARIABLE>" or "flag:--<name>". A resolved secret reports where its value was read,
+// e.g. "env:PG_PASS" for "env://PG_PASS", the secret file for "file://" and the reference itself for the other schemes.
+type ConfigSources map[string]string
+
+// Load the configuration from a file (e.g JSON, YAML, etc.) then apply the environment variables and flags on top of it.
+//
+// The file is skipped when path is empty, so the configuration can come from the environment only.
+func (l *ConfigLoader) Load(path string) (*Config, error) {
+	conf, _, err := l.LoadWithSources(path)
+	return conf, err
+}
+
+// Load the configuration like Load and report which file, environment variable or flag each value came from.
+//
+//	Example:
+//	loader := ConfigLoader{Profile: "staging"}
+//	conf, sources, err := loader.LoadWithSources("config.json")
+//	sources["sql.db_host"] // "config.staging.json"
+//	sources["sql.db_name"] // "config.json"
+func (l *ConfigLoader) LoadWithSources(path string) (*Config, ConfigSources, error) {
+	v, sources, err := l.newViper(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var conf *Config
+	err = v.Unmarshal(&conf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidConfig, err.Error())
+	}
+	if err = resolveSecrets(context.Background(), reflect.ValueOf(conf).Elem(), "", l.secretResolvers(), sources); err != nil {
+		return nil, nil, err
+	}
+	return conf, sources, nil
+}
+
+// Get the files read for the base file path: the base file followed by the profile overlay if any
+func (l *ConfigLoader) Files(path string) []string {
+	if path == "" {
+		return nil
+	}
+	if l.Profile == "" {
+		return []string{path}
+	}
+	ext := filepath.Ext(path)
+	return []string{path, strings.TrimSuffix(path, ext) + "." + l.Profile + ext}
+}
+
+func (l *ConfigLoader) secretResolvers() map[string]SecretResolver {
+	resolvers := map[string]SecretResolver{
+		"file": FileSecretResolver,
+		"env":  EnvSecretResolver,
+	}
+	for scheme, resolver := range l.SecretResolvers {
+		resolvers[scheme] = resolver
+	}
+	return resolvers
+}
+
+func (l *ConfigLoader) newViper(path string) (*viper.Viper, ConfigSources, error) {
+	v := viper.New()
+	sources := ConfigSources{}
+	for _, file := range l.Files(path) {
+		fileViper := viper.New()
+		fileViper.SetConfigFile(file)
+		err := fileViper.ReadInConfig()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, nil, fmt.Errorf("%w: %s", ErrConfigNotFound, file)
+			}
+			return nil, nil, fmt.Errorf("%w: %s", ErrInvalidConfig, err.Error())
+		}
+		// Nested sections are deep-merged, so the overlay only needs the values that differ
+		if err = v.MergeConfigMap(fileViper.AllSettings()); err != nil {
+			return nil, nil, fmt.Errorf("%w: %s", ErrInvalidConfig, err.Error())
+		}
+		for _, key := range fileViper.AllKeys() {
+			sources[key] = file
+		}
+	}
+
+	prefix := l.EnvPrefix
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	v.SetEnvPrefix(prefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	for _, key := range configKeys(reflect.TypeOf(Config{}), "") {
+		if err := v.BindEnv(key.name); err != nil {
+			return nil, nil, err
+		}
+		env := strings.ToUpper(prefix + "_" + strings.ReplaceAll(key.name, ".", "_"))
+		if _, ok := os.LookupEnv(env); ok {
+			sources[key.name] = "env:" + env
+		}
+		if l.Flags == nil {
+			continue
+		}
+		if flag := l.Flags.Lookup(key.name); flag != nil {
+			if err := v.BindPFlag(key.name, flag); err != nil {
+				return nil, nil, err
+			}
+			if flag.Changed {
+				sources[key.name] = "flag:--" + key.name
+			}
+		}
+	}
+	return v, sources, nil
+}
+
+// Register a flag for every configuration key in the flag set, e.g. --sql.db_password and --redis.db.
+//
+//	Example:
+//	RegisterConfigFlags(pflag.CommandLine)
+//	pflag.Parse()
+//	loader := ConfigLoader{Flags: pflag.CommandLine}
+func RegisterConfigFlags(fs *pflag.FlagSet) {
+	for _, key := range configKeys(reflect.TypeOf(Config{}), "") {
+		if fs.Lookup(key.name) != nil {
+			continue
+		}
+		usage := "overrides " + key.name
+		switch {
+		case key.kind == reflect.TypeOf(time.Duration(0)):
+			fs.Duration(key.name, 0, usage)
+		case key.kind.Kind() == reflect.Bool:
+			fs.Bool(key.name, false, usage)
+		case key.kind.Kind() >= reflect.Int && key.kind.Kind() <= reflect.Int64:
+			fs.Int(key.name, 0, usage)
+		case key.kind.Kind() >= reflect.Uint && key.kind.Kind() <= reflect.Uint64:
+			fs.Uint64(key.name, 0, usage)
+		case key.kind.Kind() == reflect.Float64:
+			fs.Float64(key.name, 0, usage)
+		default:
+			fs.String(key.name, "", usage)
+		}
+	}
+}
+
+type configKey struct {
+	name string
+	kind reflect.Type
+}
+
+// List every leaf key of a config struct by following its mapstructure tags
+func configKeys(t reflect.Type, prefix string) []configKey {
+	var keys []configKey
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		name := prefix + tag
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			keys = append(keys, configKeys(field.Type, name+".")...)
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+			// lists of sections can only be set from the configuration file
+		default:
+			keys = append(keys, configKey{name: name, kind: field.Type})
+		}
+	}
+	return keys
+}
+
+// Check every configured section and report all the problems at once.
+// Sections that are left empty in the configuration file are skipped.
+//
+//	Example:
+//	if err := conf.Validate(); err != nil {
+//		log.Fatal(err) // invalid config: sql.driver: must be one of mysql, postgres, got "postgress"; sql.db_port: ...
+//	}
+func (c *Config) Validate() error {
+	var errs ValidationErrors
+	if !reflect.ValueOf(c.ApiConfig).IsZero() {
+		errs = append(errs, c.ApiConfig.validate("restapi.")...)
+	}
+	if !reflect.ValueOf(c.SqlConfig).IsZero() {
+		errs = append(errs, c.SqlConfig.validate("sql.")...)
+	}
+	if !reflect.ValueOf(c.MongoConfig).IsZero() {
+		errs = append(errs, c.MongoConfig.validate("mongo.")...)
+	}
+	if !reflect.ValueOf(c.RedisConfig).IsZero() {
+		errs = append(errs, c.RedisConfig.validate("redis.")...)
+	}
+	return errs.err()
+}
+
+// Load application configuration from common configuration file (e.g JSON, YAML, etc.)
+//
+// Environment variables prefixed with DefaultEnvPrefix override the file, see ConfigLoader.
+func LoadConfigFromFile(filepath string) (*Config, error) {
+	loader := ConfigLoader{}
+	return loader.Load(filepath)
+}
+
+// Load application configuration from a base file deep-merged with the overlay of a profile (e.g "staging" reads
+// config.staging.json on top of config.json), and report which file each value came from.
+func LoadConfigWithProfile(filepath, profile string) (*Config, ConfigSources, error) {
+	loader := ConfigLoader{Profile: profile}
+	return loader.LoadWithSources(filepath)
+}
+
 // Get application configuration from common configuration file (e.g JSON, YAML, etc.)
+//
+// It exits the process when the file cannot be loaded, use LoadConfigFromFile to handle the error instead.
 func GetConfigFromFile(filepath string) *Config {
-	viper.SetConfigFile(filepath)
-	err := viper.ReadInConfig()
+	conf, err := LoadConfigFromFile(filepath)
 	if err != nil {
 		log.Fatalf("Fatal error config file: %s \n", err)
 	}
-	var conf *Config
-	err = viper.Unmarshal(&conf)
+	return conf
+}
+
+// Get application configuration from a base file deep-merged with the overlay of a profile
+//
+// It exits the process when the files cannot be loaded, use LoadConfigWithProfile to handle the error instead.
+func GetConfigWithProfile(filepath, profile string) *Config {
+	conf, _, err := LoadConfigWithProfile(filepath, profile)
 	if err != nil {
-		log.Fatalf("Fatal error marshal config file: %s \n", err)
+		log.Fatalf("Fatal error config file: %s \n", err)
 	}
 	return conf
 }
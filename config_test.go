@@ -0,0 +1,124 @@
+package gobe
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+func TestLoadWithSources(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.json")
+	overlay := filepath.Join(dir, "config.prod.json")
+	secretFile := filepath.Join(dir, "mongo_pass")
+	writeTestFile(t, base, `{
+		"sql": {"driver": "postgres", "db_host": "localhost", "db_name": "app", "db_password": "env://TEST_PG_PASS"},
+		"mongo": {"db_password": "file://`+secretFile+`"},
+		"redis": {"password": "plain"}
+	}`)
+	writeTestFile(t, overlay, `{"sql": {"db_host": "db.prod.internal"}}`)
+	writeTestFile(t, secretFile, "mongo-secret\n")
+	t.Setenv("TEST_PG_PASS", "pg-secret")
+	t.Setenv("GOBE_SQL_DB_PORT", "6543")
+
+	loader := ConfigLoader{Profile: "prod"}
+	conf, sources, err := loader.LoadWithSources(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key    string
+		got    string
+		want   string
+		source string
+	}{
+		{"sql.db_name", conf.SqlConfig.DBName, "app", base},
+		{"sql.db_host", conf.SqlConfig.DBHost, "db.prod.internal", overlay},
+		{"sql.db_port", conf.SqlConfig.DBPort, "6543", "env:GOBE_SQL_DB_PORT"},
+		{"sql.db_password", conf.SqlConfig.DBPassword.Value(), "pg-secret", "env:TEST_PG_PASS"},
+		{"mongo.db_password", conf.MongoConfig.DBPassword.Value(), "mongo-secret", secretFile},
+		{"redis.password", conf.RedisConfig.Password.Value(), "plain", base},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("value %q, want %q", tt.got, tt.want)
+			}
+			if got := sources[tt.key]; got != tt.source {
+				t.Errorf("source %q, want %q", got, tt.source)
+			}
+		})
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDataSourceNameReference(t *testing.T) {
+	dir := t.TempDir()
+	dbFile := filepath.Join(dir, "app.db")
+	fileDSN := "file://" + dbFile + "?cache=shared"
+	path := filepath.Join(dir, "config.json")
+	t.Setenv("TEST_DSN", fileDSN)
+
+	tests := []struct {
+		name   string
+		dsn    string
+		want   string
+		source string
+	}{
+		{"sqlite file uri", fileDSN, fileDSN, path},
+		{"sqlite file uri without query", "file://" + dbFile, "file://" + dbFile, path},
+		{"explicit env reference", "secret+env://TEST_DSN", fileDSN, "env:TEST_DSN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeTestFile(t, path, `{"sql": {"driver": "sqlite", "data_source_name": "`+tt.dsn+`"}}`)
+			conf, sources, err := (&ConfigLoader{}).LoadWithSources(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := conf.SqlConfig.DataSourceName.Value(); got != tt.want {
+				t.Errorf("data_source_name %q, want %q", got, tt.want)
+			}
+			if source := sources["sql.data_source_name"]; source != tt.source {
+				t.Errorf("source %q, want %q", source, tt.source)
+			}
+
+			conn, err := NewGormConnector(&conf.SqlConfig)
+			if err != nil {
+				t.Fatalf("connect: %v", err)
+			}
+			conn.Close()
+		})
+	}
+}
+
+func TestLoadMySQLPort(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeTestFile(t, path, `{"sql": {"driver": "mysql", "db_host": "db.internal", "db_port": "3306", "db_username": "app", "db_password": "p@ss:w/rd", "db_name": "shop"}}`)
+	t.Setenv("GOBE_SQL_DB_PORT", "3307")
+
+	conf, err := (&ConfigLoader{}).Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dsn, err := setDataSourceName(&conf.SqlConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := mysqldriver.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("parse %q: %v", dsn, err)
+	}
+	if parsed.Addr != "db.internal:3307" || parsed.Passwd != "p@ss:w/rd" || parsed.DBName != "shop" {
+		t.Errorf("got %s@%s/%s, want the env port and the password kept as is", parsed.Passwd, parsed.Addr, parsed.DBName)
+	}
+}
@@ -0,0 +1,218 @@
+package gobe
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ConfigWatcher keeps a configuration up to date with its file and the overlay of its profile if any.
+//
+// The files are reloaded and validated every time one of them changes. A version that cannot be loaded or fails Validate
+// never replaces the running config, the error is reported to the OnError callbacks instead.
+type ConfigWatcher struct {
+	loader   ConfigLoader
+	filepath string
+	files    []string
+	watcher  *fsnotify.Watcher
+	done     chan struct{}
+	closed   sync.Once
+	closeErr error
+
+	reloadMu    sync.Mutex
+	mu          sync.RWMutex
+	current     *Config
+	subscribers []func(old, new *Config)
+	onError     []func(err error)
+}
+
+// Load and validate the configuration file then watch it for changes.
+//
+//	Example:
+//	watcher, err := NewConfigWatcher(ConfigLoader{}, "config.json")
+//	watcher.OnSqlChange(func(old, new SqlBaseConfig) {
+//		if old.GormConfig.DebugMode != new.GormConfig.DebugMode { ... }
+//	})
+//	defer watcher.Close()
+func NewConfigWatcher(loader ConfigLoader, path string) (*ConfigWatcher, error) {
+	conf, err := loader.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, file := range loader.Files(path) {
+		file = filepath.Clean(file)
+		files = append(files, file)
+		// Watch the directory instead of the file, so editors and mounted volumes replacing the file are also caught
+		if err = fsWatcher.Add(filepath.Dir(file)); err != nil {
+			fsWatcher.Close()
+			return nil, err
+		}
+	}
+
+	w := &ConfigWatcher{
+		loader:   loader,
+		filepath: path,
+		files:    files,
+		watcher:  fsWatcher,
+		done:     make(chan struct{}),
+		current:  conf,
+	}
+	go w.watch()
+	return w, nil
+}
+
+// Get the running configuration. It must not be modified by the caller
+func (w *ConfigWatcher) Config() *Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// Register a function called with the old and new configuration every time it changes
+func (w *ConfigWatcher) Subscribe(fn func(old, new *Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Register a function called every time the "restapi" section changes
+func (w *ConfigWatcher) OnApiChange(fn func(old, new RestApiBaseConfig)) {
+	w.Subscribe(func(old, new *Config) {
+		if !reflect.DeepEqual(old.ApiConfig, new.ApiConfig) {
+			fn(old.ApiConfig, new.ApiConfig)
+		}
+	})
+}
+
+// Register a function called every time the "sql" section changes
+func (w *ConfigWatcher) OnSqlChange(fn func(old, new SqlBaseConfig)) {
+	w.Subscribe(func(old, new *Config) {
+		if !reflect.DeepEqual(old.SqlConfig, new.SqlConfig) {
+			fn(old.SqlConfig, new.SqlConfig)
+		}
+	})
+}
+
+// Register a function called every time the "mongo" section changes
+func (w *ConfigWatcher) OnMongoChange(fn func(old, new MongoBaseConfig)) {
+	w.Subscribe(func(old, new *Config) {
+		if !reflect.DeepEqual(old.MongoConfig, new.MongoConfig) {
+			fn(old.MongoConfig, new.MongoConfig)
+		}
+	})
+}
+
+// Register a function called every time the "redis" section changes
+func (w *ConfigWatcher) OnRedisChange(fn func(old, new RedisBaseConfig)) {
+	w.Subscribe(func(old, new *Config) {
+		if !reflect.DeepEqual(old.RedisConfig, new.RedisConfig) {
+			fn(old.RedisConfig, new.RedisConfig)
+		}
+	})
+}
+
+// Register a function called when a changed file is rejected because it cannot be loaded or is invalid
+func (w *ConfigWatcher) OnError(fn func(err error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onError = append(w.onError, fn)
+}
+
+// Load the file again and notify the subscribers when the configuration changed.
+// The running config is kept when the new version cannot be loaded or is invalid.
+func (w *ConfigWatcher) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	conf, err := w.loader.Load(w.filepath)
+	if err == nil && reflect.ValueOf(*conf).IsZero() {
+		// Most likely the file was read while being truncated by the editor
+		err = fmt.Errorf("%w: %s is empty", ErrInvalidConfig, w.filepath)
+	}
+	if err == nil {
+		err = conf.Validate()
+	}
+
+	w.mu.Lock()
+	old := w.current
+	onError := w.onError
+	subscribers := w.subscribers
+	changed := err == nil && !reflect.DeepEqual(old, conf)
+	if changed {
+		w.current = conf
+	}
+	w.mu.Unlock()
+
+	if err != nil {
+		log.Printf("config %s was not reloaded: %s", w.filepath, err.Error())
+		for _, fn := range onError {
+			fn(err)
+		}
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	for _, fn := range subscribers {
+		fn(old, conf)
+	}
+	return nil
+}
+
+// Stop watching the file. It is safe to call several times, even concurrently
+func (w *ConfigWatcher) Close() error {
+	w.closed.Do(func() {
+		close(w.done)
+		w.closeErr = w.watcher.Close()
+	})
+	return w.closeErr
+}
+
+func (w *ConfigWatcher) watch() {
+	realPaths := make(map[string]string, len(w.files))
+	for _, file := range w.files {
+		realPaths[file], _ = filepath.EvalSymlinks(file)
+	}
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			changed := false
+			for _, file := range w.files {
+				// Mounted secrets and config maps swap a symlink instead of writing the file
+				realPath, _ := filepath.EvalSymlinks(file)
+				written := filepath.Clean(event.Name) == file && event.Op&(fsnotify.Write|fsnotify.Create) != 0
+				swapped := realPath != "" && realPath != realPaths[file]
+				if written || swapped {
+					realPaths[file] = realPath
+					changed = true
+				}
+			}
+			if changed {
+				w.Reload()
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("failed to watch config %s with error: %s", w.filepath, err.Error())
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package gobe
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfigWatcherReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeTestFile(t, path, `{"redis": {"host": "localhost", "port": "6379"}}`)
+	watcher, err := NewConfigWatcher(ConfigLoader{}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	changes := make(chan RedisBaseConfig, 10)
+	watcher.OnRedisChange(func(old, new RedisBaseConfig) { changes <- new })
+	rejected := make(chan error, 10)
+	watcher.OnError(func(err error) { rejected <- err })
+
+	writeTestFile(t, path, `{"redis": {"host": "cache.internal", "port": "6379"}}`)
+	select {
+	case conf := <-changes:
+		if conf.Host != "cache.internal" {
+			t.Errorf("host %q, want cache.internal", conf.Host)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the change was not received")
+	}
+	if host := watcher.Config().RedisConfig.Host; host != "cache.internal" {
+		t.Errorf("running host %q, want cache.internal", host)
+	}
+
+	// An invalid version is reported and never replaces the running config
+	writeTestFile(t, path, `{"redis": {"host": "cache.internal", "port": "http"}}`)
+	timeout := time.After(5 * time.Second)
+	for invalid := false; !invalid; {
+		select {
+		case err := <-rejected:
+			// The file may also be read while truncated, which is reported as well
+			var errs ValidationErrors
+			invalid = errors.As(err, &errs)
+		case <-timeout:
+			t.Fatal("the invalid config was not reported")
+		}
+	}
+	if port := watcher.Config().RedisConfig.Port; port != "6379" {
+		t.Errorf("running port %q, want the valid config kept", port)
+	}
+}
+
+func TestConfigWatcherConcurrentClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeTestFile(t, path, `{"redis": {"host": "localhost", "port": "6379"}}`)
+	watcher, err := NewConfigWatcher(ConfigLoader{}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := watcher.Close(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
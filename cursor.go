@@ -0,0 +1,196 @@
+package gobe
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// A column of the sort order of a cursor pagination. The last key must be unique, e.g. the primary key, so no record is skipped
+//
+//	Example:
+//	[]SortKey{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}}
+type SortKey struct {
+	Column string
+	Desc   bool
+}
+
+// Cursors of the pages around a page of a cursor pagination, they are empty when there is no such page
+type CursorInfo struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	HasNext    bool   `json:"has_next"`
+	HasPrev    bool   `json:"has_prev"`
+}
+
+// A page of records of a cursor pagination
+type CursorPage[T any] struct {
+	Items []T `json:"items"`
+	CursorInfo
+}
+
+// Content of a cursor, the sort key values of the record it points at
+type cursorToken struct {
+	Values   []json.RawMessage `json:"v"`
+	Backward bool              `json:"b,omitempty"`
+}
+
+// Find the page of records after or before the cursor into dest, which is a pointer to a slice. The first page has no cursor
+func findCursorPage(db *gorm.DB, dest interface{}, sort []SortKey, cursor string, limit int) (CursorInfo, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(dest); err != nil {
+		return CursorInfo{}, err
+	}
+	fields, err := sortFields(stmt.Schema, sort)
+	if err != nil {
+		return CursorInfo{}, err
+	}
+	if len(sort) == 0 {
+		sort = []SortKey{{Column: fields[0].DBName}}
+	}
+	if limit < 1 {
+		limit = defaultItemPerPage
+	}
+
+	var token cursorToken
+	if cursor != "" {
+		if token, err = decodeCursor(cursor, len(fields)); err != nil {
+			return CursorInfo{}, err
+		}
+		condition, err := keysetCondition(fields, sort, token)
+		if err != nil {
+			return CursorInfo{}, err
+		}
+		db = db.Where(condition)
+	}
+	for i, field := range fields {
+		// A previous page is read backward from the cursor then put back in order
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Desc: sort[i].Desc != token.Backward})
+	}
+	if err := db.Limit(limit + 1).Find(dest).Error; err != nil {
+		return CursorInfo{}, err
+	}
+
+	items := reflect.ValueOf(dest).Elem()
+	hasMore := items.Len() > limit
+	if hasMore {
+		items.Set(items.Slice(0, limit))
+	}
+	if token.Backward {
+		for i, j := 0, items.Len()-1; i < j; i, j = i+1, j-1 {
+			first, last := items.Index(i).Interface(), items.Index(j).Interface()
+			items.Index(i).Set(reflect.ValueOf(last))
+			items.Index(j).Set(reflect.ValueOf(first))
+		}
+	}
+
+	info := CursorInfo{HasNext: hasMore, HasPrev: cursor != ""}
+	if token.Backward {
+		info.HasNext, info.HasPrev = true, hasMore
+	}
+	if items.Len() == 0 {
+		return info, nil
+	}
+	if info.HasNext {
+		if info.NextCursor, err = encodeCursor(db.Statement.Context, fields, items.Index(items.Len()-1), false); err != nil {
+			return CursorInfo{}, err
+		}
+	}
+	if info.HasPrev {
+		if info.PrevCursor, err = encodeCursor(db.Statement.Context, fields, items.Index(0), true); err != nil {
+			return CursorInfo{}, err
+		}
+	}
+	return info, nil
+}
+
+// Get the fields of the sort keys, the primary key when there is none
+func sortFields(s *schema.Schema, sort []SortKey) ([]*schema.Field, error) {
+	if len(sort) == 0 {
+		if s.PrioritizedPrimaryField == nil {
+			return nil, fmt.Errorf("%s has no primary key, set the sort keys of the cursor", s.Name)
+		}
+		return []*schema.Field{s.PrioritizedPrimaryField}, nil
+	}
+	fields := make([]*schema.Field, len(sort))
+	for i, key := range sort {
+		field, err := lookUpColumn(s, key.Column)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = field
+	}
+	return fields, nil
+}
+
+// Match the records after the cursor in the sort order, or before it when reading backward:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+func keysetCondition(fields []*schema.Field, sort []SortKey, token cursorToken) (clause.Expression, error) {
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		value := reflect.New(field.FieldType)
+		if err := json.Unmarshal(token.Values[i], value.Interface()); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCursor, err.Error())
+		}
+		values[i] = value.Elem().Interface()
+	}
+
+	var conditions []clause.Expression
+	for i, field := range fields {
+		var and []clause.Expression
+		for j := 0; j < i; j++ {
+			and = append(and, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: fields[j].DBName}, Value: values[j]})
+		}
+		column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+		if sort[i].Desc != token.Backward {
+			and = append(and, clause.Lt{Column: column, Value: values[i]})
+		} else {
+			and = append(and, clause.Gt{Column: column, Value: values[i]})
+		}
+		conditions = append(conditions, clause.And(and...))
+	}
+	// A lone condition or a bare OR would be joined with OR to the conditions of the caller
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+	return clause.And(clause.Or(conditions...)), nil
+}
+
+// The cursor is URL safe, so it can be passed as is in a query parameter
+func encodeCursor(ctx context.Context, fields []*schema.Field, item reflect.Value, backward bool) (string, error) {
+	token := cursorToken{Backward: backward}
+	for _, field := range fields {
+		value, _ := field.ValueOf(ctx, item)
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		token.Values = append(token.Values, raw)
+	}
+	res, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(res), nil
+}
+
+func decodeCursor(cursor string, keys int) (cursorToken, error) {
+	var token cursorToken
+	res, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		err = json.Unmarshal(res, &token)
+	}
+	if err != nil {
+		return token, fmt.Errorf("%w: %s", ErrInvalidCursor, err.Error())
+	}
+	if len(token.Values) != keys {
+		return token, fmt.Errorf("%w: it was made for another sort order", ErrInvalidCursor)
+	}
+	return token, nil
+}
@@ -0,0 +1,99 @@
+package gobe
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type cursorItem struct {
+	ID        uint
+	Status    string
+	CreatedAt time.Time
+}
+
+func TestFindAllByWithCursor(t *testing.T) {
+	db := newTestDB(t, &cursorItem{})
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Records 3 and 4 share a creation time, so the id breaks the tie
+	items := []cursorItem{
+		{ID: 1, Status: "a", CreatedAt: base},
+		{ID: 2, Status: "b", CreatedAt: base.Add(time.Hour)},
+		{ID: 3, Status: "a", CreatedAt: base.Add(2 * time.Hour)},
+		{ID: 4, Status: "a", CreatedAt: base.Add(2 * time.Hour)},
+		{ID: 5, Status: "b", CreatedAt: base.Add(3 * time.Hour)},
+		{ID: 6, Status: "a", CreatedAt: base.Add(4 * time.Hour)},
+		{ID: 7, Status: "a", CreatedAt: base.Add(5 * time.Hour)},
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+	repo := &Repository[cursorItem]{Db: db}
+
+	tests := []struct {
+		name string
+		by   map[string]interface{}
+		sort []SortKey
+		want []uint
+	}{
+		{"primary key", nil, nil, []uint{1, 2, 3, 4, 5, 6, 7}},
+		{"primary key with condition", map[string]interface{}{"status": "a"}, nil, []uint{1, 3, 4, 6, 7}},
+		{"single key desc with condition", map[string]interface{}{"status": "a"}, []SortKey{{Column: "id", Desc: true}}, []uint{7, 6, 4, 3, 1}},
+		{"multiple keys with condition", map[string]interface{}{"status": "a"}, []SortKey{{Column: "created_at", Desc: true}, {Column: "id"}}, []uint{7, 6, 3, 4, 1}},
+		{"multiple keys", nil, []SortKey{{Column: "created_at"}, {Column: "id", Desc: true}}, []uint{1, 2, 4, 3, 5, 6, 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			var got []uint
+			var pages []CursorPage[cursorItem]
+			cursor := ""
+			for {
+				page, err := repo.FindAllByWithCursor(ctx, tt.by, tt.sort, cursor, 2)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, item := range page.Items {
+					got = append(got, item.ID)
+				}
+				pages = append(pages, page)
+				if !page.HasNext {
+					break
+				}
+				if len(pages) > len(items) {
+					t.Fatal("the pagination doesn't end")
+				}
+				cursor = page.NextCursor
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("forward: got %v, want %v", got, tt.want)
+			}
+
+			// Read the pages again backward from the last one
+			for i := len(pages) - 1; i > 0; i-- {
+				page, err := repo.FindAllByWithCursor(ctx, tt.by, tt.sort, pages[i].PrevCursor, 2)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(page.Items, pages[i-1].Items) {
+					t.Fatalf("backward from page %d: got %v, want %v", i+1, page.Items, pages[i-1].Items)
+				}
+			}
+		})
+	}
+}
+
+func TestFindAllByWithCursorErrors(t *testing.T) {
+	db := newTestDB(t, &cursorItem{})
+	repo := &Repository[cursorItem]{Db: db}
+	ctx := context.Background()
+
+	if _, err := repo.FindAllByWithCursor(ctx, nil, []SortKey{{Column: "nope"}}, "", 2); err == nil {
+		t.Error("unknown column: expected an error")
+	}
+	if _, err := repo.FindAllByWithCursor(ctx, nil, nil, "not a cursor", 2); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("malformed cursor: got %v, want ErrInvalidCursor", err)
+	}
+}
@@ -0,0 +1,65 @@
+package gobe
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// A capability of the SQL dialect that not every driver has
+type DialectFeature string
+
+const (
+	// Generated primary keys are read back after an insert (RETURNING, OUTPUT or LAST_INSERT_ID)
+	FeatureReturning DialectFeature = `returning`
+	// Insert or update on conflict (ON CONFLICT, ON DUPLICATE KEY or MERGE)
+	FeatureUpsert DialectFeature = `upsert`
+	// Atomic transactions with rollback
+	FeatureTransactions DialectFeature = `transactions`
+	// Synchronous UPDATE and DELETE reporting the number of affected rows
+	FeatureRowsAffected DialectFeature = `rows_affected`
+)
+
+// Features missing per driver, every other driver supports all of them
+var unsupportedFeatures = map[DBDriver][]DialectFeature{
+	// Updates and deletes are asynchronous mutations (ALTER TABLE ... UPDATE) and inserts are plain batches
+	Clickhouse: {FeatureReturning, FeatureUpsert, FeatureTransactions, FeatureRowsAffected},
+}
+
+// UnsupportedFeatureError is returned by the repositories when an operation needs a feature the driver doesn't have.
+// It matches ErrUnsupportedFeature when checked with errors.Is.
+type UnsupportedFeatureError struct {
+	Driver  DBDriver
+	Feature DialectFeature
+}
+
+func (e *UnsupportedFeatureError) Error() string {
+	return fmt.Sprintf("%s: %s is not supported by %s", ErrUnsupportedFeature.Error(), e.Feature, e.Driver)
+}
+
+func (e *UnsupportedFeatureError) Is(target error) bool {
+	return target == ErrUnsupportedFeature
+}
+
+// Tell whether a driver supports a dialect feature
+func SupportsFeature(driver DBDriver, feature DialectFeature) bool {
+	for _, f := range unsupportedFeatures[driver] {
+		if f == feature {
+			return false
+		}
+	}
+	return true
+}
+
+// Get the driver behind a GORM connection, the names of the GORM dialectors match the DBDriver constants
+func gormDriver(db *gorm.DB) DBDriver {
+	return DBDriver(db.Dialector.Name())
+}
+
+func requireFeature(db *gorm.DB, feature DialectFeature) error {
+	driver := gormDriver(db)
+	if !SupportsFeature(driver, feature) {
+		return &UnsupportedFeatureError{Driver: driver, Feature: feature}
+	}
+	return nil
+}
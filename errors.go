@@ -0,0 +1,51 @@
+package gobe
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// The configuration file does not exist
+	ErrConfigNotFound = errors.New("config file not found")
+	// The configuration file exists but cannot be read or decoded
+	ErrInvalidConfig = errors.New("invalid config")
+	// The configured driver is not supported by the connector
+	ErrUnsupportedDriver = errors.New("unsupported driver")
+	// The server cannot be reached
+	ErrDialFailure = errors.New("dial failure")
+	// The server rejected the credentials
+	ErrAuthFailure = errors.New("authentication failure")
+	// The operation needs a feature the SQL dialect doesn't have
+	ErrUnsupportedFeature = errors.New("unsupported feature")
+	// The cursor of a cursor pagination is malformed or was made for another sort order
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// A sort or a condition names a column the model doesn't have
+	ErrUnknownColumn = errors.New("unknown column")
+	// The record was modified by someone else since it was read, see the version field of the models
+	ErrStaleObject = errors.New("stale object")
+)
+
+// ConnectionError is returned when a connector fails to reach its server.
+// Kind is either ErrDialFailure or ErrAuthFailure, so it can be checked with errors.Is.
+//
+//	Example:
+//	_, err := NewGormConnector(&cfg.SqlConfig)
+//	if errors.Is(err, ErrAuthFailure) { ... }
+type ConnectionError struct {
+	Kind   error
+	Target string
+	Err    error
+}
+
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Target, e.Kind.Error(), e.Err.Error())
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ConnectionError) Is(target error) bool {
+	return e.Kind == target
+}
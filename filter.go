@@ -0,0 +1,140 @@
+package gobe
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Filter is a condition of a query. The values are always bound as parameters and the columns are quoted,
+// so a filter built from user input cannot inject SQL.
+//
+//	Example:
+//	filter := And(
+//		Eq("status", "active"),
+//		Or(Like("name", "%"+search+"%"), Like("email", "%"+search+"%")),
+//		Between("created_at", from, to),
+//	)
+type Filter struct {
+	expr clause.Expression
+	err  error
+}
+
+var columnPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+func newFilter(column string, build func(col clause.Column) clause.Expression) Filter {
+	if !columnPattern.MatchString(column) {
+		return Filter{err: fmt.Errorf("invalid column name %q", column)}
+	}
+	return Filter{expr: build(clause.Column{Name: column})}
+}
+
+// column = value
+func Eq(column string, value interface{}) Filter {
+	return newFilter(column, func(col clause.Column) clause.Expression { return clause.Eq{Column: col, Value: value} })
+}
+
+// column <> value
+func Ne(column string, value interface{}) Filter {
+	return newFilter(column, func(col clause.Column) clause.Expression { return clause.Neq{Column: col, Value: value} })
+}
+
+// column > value
+func Gt(column string, value interface{}) Filter {
+	return newFilter(column, func(col clause.Column) clause.Expression { return clause.Gt{Column: col, Value: value} })
+}
+
+// column >= value
+func Gte(column string, value interface{}) Filter {
+	return newFilter(column, func(col clause.Column) clause.Expression { return clause.Gte{Column: col, Value: value} })
+}
+
+// column < value
+func Lt(column string, value interface{}) Filter {
+	return newFilter(column, func(col clause.Column) clause.Expression { return clause.Lt{Column: col, Value: value} })
+}
+
+// column <= value
+func Lte(column string, value interface{}) Filter {
+	return newFilter(column, func(col clause.Column) clause.Expression { return clause.Lte{Column: col, Value: value} })
+}
+
+// column IN (values...). A single slice is expanded, e.g. In("id", []int{1, 2, 3})
+func In(column string, values ...interface{}) Filter {
+	if len(values) == 1 {
+		if v := reflect.ValueOf(values[0]); v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+			values = make([]interface{}, v.Len())
+			for i := range values {
+				values[i] = v.Index(i).Interface()
+			}
+		}
+	}
+	return newFilter(column, func(col clause.Column) clause.Expression { return clause.IN{Column: col, Values: values} })
+}
+
+// column LIKE pattern, e.g. Like("name", "%"+search+"%")
+func Like(column string, pattern string) Filter {
+	return newFilter(column, func(col clause.Column) clause.Expression { return clause.Like{Column: col, Value: pattern} })
+}
+
+// column BETWEEN from AND to
+func Between(column string, from, to interface{}) Filter {
+	return newFilter(column, func(col clause.Column) clause.Expression {
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []interface{}{col, from, to}}
+	})
+}
+
+// column IS NULL
+func IsNull(column string) Filter {
+	return newFilter(column, func(col clause.Column) clause.Expression { return clause.Eq{Column: col, Value: nil} })
+}
+
+// column IS NOT NULL
+func IsNotNull(column string) Filter {
+	return newFilter(column, func(col clause.Column) clause.Expression { return clause.Neq{Column: col, Value: nil} })
+}
+
+// Match the records matching every filter
+func And(filters ...Filter) Filter {
+	return group(filters, clause.And)
+}
+
+// Match the records matching any of the filters
+func Or(filters ...Filter) Filter {
+	return group(filters, clause.Or)
+}
+
+func group(filters []Filter, join func(exprs ...clause.Expression) clause.Expression) Filter {
+	exprs := make([]clause.Expression, 0, len(filters))
+	for _, f := range filters {
+		if f.err != nil {
+			return f
+		}
+		if f.expr != nil {
+			exprs = append(exprs, f.expr)
+		}
+	}
+	if len(exprs) == 0 {
+		return Filter{}
+	}
+	// A group of one would be an OR with the conditions around it
+	if len(exprs) == 1 {
+		return Filter{expr: exprs[0]}
+	}
+	return Filter{expr: join(exprs...)}
+}
+
+// Apply the filter to the query, an empty filter matches every record
+func (f Filter) apply(db *gorm.DB) *gorm.DB {
+	if f.err != nil {
+		db.AddError(f.err)
+		return db
+	}
+	if f.expr == nil {
+		return db
+	}
+	return db.Where(f.expr)
+}
@@ -0,0 +1,79 @@
+package gobe
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type filterItem struct {
+	ID     uint
+	Status string
+	Price  int
+	Note   *string
+}
+
+// Render the SELECT of the filter without running it
+func filterSQL(t *testing.T, db *gorm.DB, filter Filter) string {
+	t.Helper()
+	stmt := filter.apply(db.Session(&gorm.Session{DryRun: true})).Where("price > ?", 0).Find(&[]filterItem{}).Statement
+	if err := stmt.Error; err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	return db.Dialector.Explain(stmt.SQL.String(), stmt.Vars...)
+}
+
+func TestFilterSQL(t *testing.T) {
+	db := newTestDB(t, &filterItem{})
+	const from = "SELECT * FROM `filter_items` WHERE "
+
+	tests := []struct {
+		name   string
+		filter Filter
+		where  string
+	}{
+		{"empty", Filter{}, "price > 0"},
+		{"empty group", And(), "price > 0"},
+		{"empty nested groups", And(Or(), And()), "price > 0"},
+		{"eq", Eq("status", "a"), "`status` = \"a\" AND price > 0"},
+		{"single child or", Or(Eq("status", "a")), "`status` = \"a\" AND price > 0"},
+		{"single child or in and", And(Eq("status", "a"), Or(Eq("id", 2))), "(`status` = \"a\" AND `id` = 2) AND price > 0"},
+		{"single child and in or", Or(And(Eq("status", "a")), Eq("id", 2)), "(`status` = \"a\" OR `id` = 2) AND price > 0"},
+		{"nested groups", And(Eq("status", "a"), Or(Gt("price", 10), IsNull("note"))), "(`status` = \"a\" AND (`price` > 10 OR `note` IS NULL)) AND price > 0"},
+		{"in", In("id", []int{1, 2}), "`id` IN (1,2) AND price > 0"},
+		{"between", Between("price", 1, 5), "(`price` BETWEEN 1 AND 5) AND price > 0"},
+		{"ne and like", And(Ne("status", "b"), Like("status", "%a%")), "(`status` <> \"b\" AND `status` LIKE \"%a%\") AND price > 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterSQL(t, db, tt.filter)
+			if want := from + tt.where; got != want {
+				t.Errorf("got  %s\nwant %s", got, want)
+			}
+		})
+	}
+}
+
+func TestFilterInvalidColumn(t *testing.T) {
+	db := newTestDB(t, &filterItem{})
+	err := And(Eq("status", "a"), Or(Eq("id; DROP TABLE filter_items", 1))).apply(db).Find(&[]filterItem{}).Error
+	if err == nil || !strings.Contains(err.Error(), "invalid column name") {
+		t.Fatalf("got %v, want an invalid column name error", err)
+	}
+}
+
+func TestFindAllByFilter(t *testing.T) {
+	db := newTestDB(t, &filterItem{})
+	db.Create(&[]filterItem{{Status: "a", Price: 5}, {Status: "a", Price: 20}, {Status: "b", Price: 30}})
+	repo := &Repository[filterItem]{Db: db}
+
+	items, err := repo.FindAllByFilter(context.Background(), And(Eq("status", "a"), Or(Gte("price", 10))), "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Price != 20 {
+		t.Fatalf("got %v, want the record at 20", items)
+	}
+}
@@ -1,9 +1,11 @@
 package gobe
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // ==SUCCESS RESPONSES (2xx)==
@@ -23,6 +25,32 @@ func SuccessWithMessage(c *gin.Context, message interface{}) {
 	})
 }
 
+// Return status 200 with a page of items and its position in the whole result
+//
+//	Example:
+//	page, err := repo.FindAllByWithPagination(ctx, by, 2, 10, "created_at desc")
+//	SuccessWithPage(c, page.Items, page.PageInfo)
+func SuccessWithPage(c *gin.Context, items interface{}, info PageInfo) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":     "SUCCESS",
+		"data":       items,
+		"pagination": info,
+	})
+}
+
+// Return status 200 with a page of a cursor pagination and the cursors of the pages around it
+//
+//	Example:
+//	page, err := repo.FindAllByWithCursor(ctx, by, sort, c.Query("cursor"), 10)
+//	SuccessWithCursor(c, page.Items, page.CursorInfo)
+func SuccessWithCursor(c *gin.Context, items interface{}, info CursorInfo) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":     "SUCCESS",
+		"data":       items,
+		"pagination": info,
+	})
+}
+
 // 	==CLIENT ERROR RESPONSES (4xx)==
 
 // Abort and return error status 400
@@ -70,6 +98,36 @@ func UnauthorizedErrorWithMessage(c *gin.Context, message interface{}) {
 	})
 }
 
+// Abort and return error status 404
+func NotFoundError(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+		"status": "FAILED",
+	})
+}
+
+// Abort and return error status 404 with a specific message
+func NotFoundErrorWithMessage(c *gin.Context, message interface{}) {
+	c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+		"status":  "FAILED",
+		"message": message,
+	})
+}
+
+// Abort and return error status 409
+func ConflictError(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+		"status": "FAILED",
+	})
+}
+
+// Abort and return error status 409 with a specific message
+func ConflictErrorWithMessage(c *gin.Context, message interface{}) {
+	c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+		"status":  "FAILED",
+		"message": message,
+	})
+}
+
 // ==SERVER ERROR RESPONSES(5xx)==
 
 // Abort and return error status 500
@@ -86,3 +144,24 @@ func InternalServerErrorWithMessage(c *gin.Context, message interface{}) {
 		"message": message,
 	})
 }
+
+// Abort with the status matching an error of a repository: 409 for ErrStaleObject, 404 for gorm.ErrRecordNotFound,
+// 400 for ErrUnknownColumn and ErrInvalidCursor, and 500 without the details of the error for anything else
+//
+//	Example:
+//	if err := repo.UpdateBy(ctx, by, value); err != nil {
+//		RepositoryError(c, err)
+//		return
+//	}
+func RepositoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrStaleObject):
+		ConflictErrorWithMessage(c, "the record was modified by someone else, reload it and try again")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		NotFoundError(c)
+	case errors.Is(err, ErrUnknownColumn), errors.Is(err, ErrInvalidCursor):
+		BadRequestErrorWithMessage(c, err.Error())
+	default:
+		InternalServerError(c)
+	}
+}
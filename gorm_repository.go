@@ -1,179 +1,723 @@
 package gobe
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
 )
 
 // Initiate a GORM repository
+//
+// When the connection has read replicas the Find* methods read from them, every other method writes to the primary.
+// Repository is the type-safe version of it, sharing the same queries.
 type GormRepository struct {
 	Db *gorm.DB
+	// Default timeout of every query, e.g. from the query_timeout of SqlBaseConfig. No timeout when zero
+	Timeout time.Duration
+	// Record every Create, UpdateBy, DeleteBy, Restore and ForceDelete in the audit_logs table, see AuditLog
+	Audit bool
+}
+
+// Option of a single repository call
+type QueryOption func(*queryOptions)
+
+type queryOptions struct {
+	primary bool
+	trashed trashedMode
+}
+
+// Read from the primary instead of a replica, e.g. to read a record right after writing it
+//
+//	Example:
+//	FindBy(&User{}, map[string]interface{}{"id":1}, UsePrimary())
+func UsePrimary() QueryOption {
+	return func(o *queryOptions) {
+		o.primary = true
+	}
+}
+
+func (g *GormRepository) query(ctx context.Context, opts []QueryOption) *gorm.DB {
+	return withOptions(connection(ctx, g.Db), opts)
+}
+
+// Get a copy of the repository running its queries in the transaction, e.g. the one of RunInTx.
+// The repositories called with the context of RunInTx already use it, this is for the calls without a context
+func (g *GormRepository) WithTx(tx *gorm.DB) *GormRepository {
+	return &GormRepository{Db: tx, Timeout: g.Timeout, Audit: g.Audit}
+}
+
+// Apply the options of a call to the query
+func withOptions(db *gorm.DB, opts []QueryOption) *gorm.DB {
+	var options queryOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.primary {
+		db = db.Clauses(dbresolver.Write)
+	}
+	switch options.trashed {
+	case withTrashed:
+		db = db.Unscoped()
+	case onlyTrashed:
+		db = db.Unscoped().Scopes(trashedOnly)
+	}
+	return db
+}
+
+// Load the nested associations then every direct association of the model
+func preload(db *gorm.DB, nestedPreload ...string) *gorm.DB {
+	for _, nested := range nestedPreload {
+		db = db.Preload(nested)
+	}
+	return db.Preload(clause.Associations)
+}
+
+// Bound the context by the default timeout of a repository, an earlier deadline of the context is kept
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
 }
 
 // Create/insert a new record to the table by defining the model explicitly
+//
+// The drivers that cannot read a generated primary key back (e.g. ClickHouse) return ErrUnsupportedFeature when it is left empty.
 func (g *GormRepository) Create(model interface{}) error {
-	return g.Db.Create(model).Error
+	return g.CreateContext(context.Background(), model)
+}
+
+// Create cancelled with the context or after the timeout of the repository
+func (g *GormRepository) CreateContext(ctx context.Context, model interface{}) error {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	if err := checkGeneratedKey(g.Db, model); err != nil {
+		return err
+	}
+	return auditedWrite(ctx, g.Db, g.Audit, model, nil, AuditCreate, func(db *gorm.DB) error {
+		return db.Create(model).Error
+	})
+}
+
+// Make sure the driver can fill an auto-increment primary key left empty, of a record or of every record of a slice
+func checkGeneratedKey(db *gorm.DB, model interface{}) error {
+	err := requireFeature(db, FeatureReturning)
+	if err == nil {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: db}
+	if parseErr := stmt.Parse(model); parseErr != nil {
+		return parseErr
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil || !field.AutoIncrement {
+		return nil
+	}
+	value := reflect.Indirect(reflect.ValueOf(model))
+	items := []reflect.Value{value}
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		items = make([]reflect.Value, value.Len())
+		for i := range items {
+			items[i] = reflect.Indirect(value.Index(i))
+		}
+	}
+	for _, item := range items {
+		if item.Kind() != reflect.Struct {
+			continue
+		}
+		if _, zero := field.ValueOf(context.Background(), item); zero {
+			return fmt.Errorf("%w, set %s before creating the record", err, field.Name)
+		}
+	}
+	return nil
 }
 
 // Update a value in a record.
 //
+// A model with an integer field tagged `gobe:"version"` is locked optimistically: every update increments the version,
+// and when the values, the conditions or the model hold the version the caller read, the update returns ErrStaleObject if the record has another one.
+//
 //	Example:
-//	UpdateBy(User, map[string]interface{}{"id":1}, map[string]interface{}{"name":"YYY"}) // will update a User record name with ID = 1 to "YYY"
+//	UpdateBy(&User{}, map[string]interface{}{"id":1}, map[string]interface{}{"name":"YYY"}) // will update a User record name with ID = 1 to "YYY"
+//	UpdateBy(&User{}, map[string]interface{}{"id":1}, map[string]interface{}{"name":"YYY", "version":3}) // will fail if the record is not at version 3 anymore
 func (g *GormRepository) UpdateBy(model interface{}, by map[string]interface{}, value map[string]interface{}) error {
-	return g.Db.Model(model).Where(by).Updates(value).Error
+	return g.UpdateByContext(context.Background(), model, by, value)
 }
 
-// Delete a record.
+// UpdateBy cancelled with the context or after the timeout of the repository
+func (g *GormRepository) UpdateByContext(ctx context.Context, model interface{}, by map[string]interface{}, value map[string]interface{}) error {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	return auditedWrite(ctx, g.Db, g.Audit, model, by, AuditUpdate, func(db *gorm.DB) error {
+		return updateBy(db, model, by, value)
+	})
+}
+
+// Delete a record. A model with a gorm.DeletedAt field is soft deleted, see Restore and ForceDelete
 //
 //	Example:
-//	DeleteBy(User, map[string]interface{}{"id":1}) // will delete a User record name with ID = 1
+//	DeleteBy(&User{}, map[string]interface{}{"id":1}) // will delete a User record name with ID = 1
 func (g *GormRepository) DeleteBy(model interface{}, by map[string]interface{}) error {
-	return g.Db.Model(model).Delete(by).Error
+	return g.DeleteByContext(context.Background(), model, by)
+}
+
+// DeleteBy cancelled with the context or after the timeout of the repository
+func (g *GormRepository) DeleteByContext(ctx context.Context, model interface{}, by map[string]interface{}) error {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	return auditedWrite(ctx, g.Db, g.Audit, model, by, AuditDelete, func(db *gorm.DB) error {
+		return db.Where(by).Delete(model).Error
+	})
+}
+
+// Restore the soft deleted records matching the conditions. The model must have a gorm.DeletedAt field
+//
+//	Example:
+//	Restore(&User{}, map[string]interface{}{"id":1}) // will restore the User record with ID = 1
+func (g *GormRepository) Restore(model interface{}, by map[string]interface{}) error {
+	return g.RestoreContext(context.Background(), model, by)
+}
+
+// Restore cancelled with the context or after the timeout of the repository
+func (g *GormRepository) RestoreContext(ctx context.Context, model interface{}, by map[string]interface{}) error {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	return auditedWrite(ctx, g.Db, g.Audit, model, by, AuditUpdate, func(db *gorm.DB) error {
+		return restore(db, model, by)
+	})
+}
+
+// Delete the records matching the conditions for good, soft deleted or not
+//
+//	Example:
+//	ForceDelete(&User{}, map[string]interface{}{"id":1}) // will remove the User record with ID = 1 from the table
+func (g *GormRepository) ForceDelete(model interface{}, by map[string]interface{}) error {
+	return g.ForceDeleteContext(context.Background(), model, by)
+}
+
+// ForceDelete cancelled with the context or after the timeout of the repository
+func (g *GormRepository) ForceDeleteContext(ctx context.Context, model interface{}, by map[string]interface{}) error {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	return auditedWrite(ctx, g.Db, g.Audit, model, by, AuditDelete, func(db *gorm.DB) error {
+		return db.Unscoped().Where(by).Delete(model).Error
+	})
+}
+
+// Find the audit log entries of a record, the oldest first. id is the primary key, or a slice of its values for a composite key
+//
+//	Example:
+//	History(&User{}, 1) // will get the changes of the User record with ID = 1
+func (g *GormRepository) History(model interface{}, id interface{}) ([]AuditLog, error) {
+	return g.HistoryContext(context.Background(), model, id)
+}
+
+// History cancelled with the context or after the timeout of the repository
+func (g *GormRepository) HistoryContext(ctx context.Context, model interface{}, id interface{}) ([]AuditLog, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	return auditHistory(connection(ctx, g.Db), model, id)
+}
+
+// Insert the records of a slice, batchSize records per statement, in a transaction. Returns the number of inserted records.
+// batchSize defaults to 1000 when it is zero.
+//
+//	Example:
+//	CreateInBatches(&[]User{...}, 500) // will insert the users 500 at a time
+func (g *GormRepository) CreateInBatches(model interface{}, batchSize int) (int64, error) {
+	return g.CreateInBatchesContext(context.Background(), model, batchSize)
+}
+
+// CreateInBatches cancelled with the context or after the timeout of the repository
+func (g *GormRepository) CreateInBatchesContext(ctx context.Context, model interface{}, batchSize int) (int64, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	return createInBatches(ctx, g.Db, model, batchSize)
+}
+
+// Insert a record or the records of a slice, updating the updateColumns of the records conflicting on the conflictColumns instead.
+// Returns the number of inserted and updated records. A key repeated in the input is written and counted once, with its last item.
+//
+// The conflict columns default to the primary key and must have a unique index, every column but the primary key is updated when updateColumns is empty.
+// MySQL ignores the conflict columns and updates on any unique index, and only counts the updated records whose values changed.
+// The drivers without upsert (e.g. ClickHouse) return ErrUnsupportedFeature.
+//
+//	Example:
+//	Upsert(&[]Product{...}, []string{"sku"}, []string{"name", "price"}) // will insert the new SKUs and update the name and price of the others
+func (g *GormRepository) Upsert(model interface{}, conflictColumns, updateColumns []string) (UpsertResult, error) {
+	return g.UpsertContext(context.Background(), model, conflictColumns, updateColumns)
+}
+
+// Upsert cancelled with the context or after the timeout of the repository
+func (g *GormRepository) UpsertContext(ctx context.Context, model interface{}, conflictColumns, updateColumns []string) (UpsertResult, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	return upsert(ctx, g.Db, model, conflictColumns, updateColumns)
 }
 
 // Find a record by using the row name and the data.
 //
 //	Example:
-//	FindBy(&User, map[string]interface{}{"id":1}) // will get result User with ID = 1
-func (g *GormRepository) FindBy(model interface{}, by map[string]interface{}) (interface{}, error) {
-	err := g.Db.Where(by).First(&model).Error
+//	FindBy(&User{}, map[string]interface{}{"id":1}) // will get result User with ID = 1
+func (g *GormRepository) FindBy(model interface{}, by map[string]interface{}, opts ...QueryOption) (interface{}, error) {
+	return g.FindByContext(context.Background(), model, by, opts...)
+}
+
+// FindBy cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindByContext(ctx context.Context, model interface{}, by map[string]interface{}, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := g.query(ctx, opts).Where(by).First(model).Error
 	return model, err
 }
 
 // Find a record by using the row name and the data. Preload will get all associations in the model
 //
 //	Example:
-//	FindByWithPreload(&User, map[string]interface{}{"id":1}) // will get result User with ID = 1
-func (g *GormRepository) FindByWithPreload(model interface{}, by map[string]interface{}) (interface{}, error) {
-	err := g.Db.Preload(clause.Associations).Where(by).First(&model).Error
+//	FindByWithPreload(&User{}, map[string]interface{}{"id":1}) // will get result User with ID = 1
+func (g *GormRepository) FindByWithPreload(model interface{}, by map[string]interface{}, opts ...QueryOption) (interface{}, error) {
+	return g.FindByWithPreloadContext(context.Background(), model, by, opts...)
+}
+
+// FindByWithPreload cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindByWithPreloadContext(ctx context.Context, model interface{}, by map[string]interface{}, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := preload(g.query(ctx, opts)).Where(by).First(model).Error
 	return model, err
 }
 
 // Find a record by using the row name and the data. Preload will get all associations in the model
 //
 //	Example:
-//	FindByWithNestedPreload(&User, map[string]interface{}{"id":1}, "User.Role") // will get result User with ID = 1
-func (g *GormRepository) FindByWithNestedPreload(model interface{}, by map[string]interface{}, nestedPreload string) (interface{}, error) {
-	err := g.Db.Preload(nestedPreload).Preload(clause.Associations).Where(by).First(&model).Error
+//	FindByWithNestedPreload(&User{}, map[string]interface{}{"id":1}, "User.Role") // will get result User with ID = 1
+func (g *GormRepository) FindByWithNestedPreload(model interface{}, by map[string]interface{}, nestedPreload string, opts ...QueryOption) (interface{}, error) {
+	return g.FindByWithNestedPreloadContext(context.Background(), model, by, nestedPreload, opts...)
+}
+
+// FindByWithNestedPreload cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindByWithNestedPreloadContext(ctx context.Context, model interface{}, by map[string]interface{}, nestedPreload string, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := preload(g.query(ctx, opts), nestedPreload).Where(by).First(model).Error
 	return model, err
 }
 
 // Find any records by using the row name and the data.
 //
 //	Example:
-//	FindAllBy(&User, map[string]interface{}{}, "created_at desc") // will get all User
-//	FindAllBy(&User, map[string]interface{}{"name":"XXX"}, "created_at desc") // will get all User with name = "XXX"
-func (g *GormRepository) FindAllBy(model interface{}, by map[string]interface{}, orderBy string) (interface{}, error) {
-	err := g.Db.Where(by).Order(orderBy).Unscoped().Find(&model).Error
+//	FindAllBy(&[]User{}, map[string]interface{}{}, "created_at desc") // will get all User
+//	FindAllBy(&[]User{}, map[string]interface{}{"name":"XXX"}, "created_at desc") // will get all User with name = "XXX"
+func (g *GormRepository) FindAllBy(model interface{}, by map[string]interface{}, orderBy string, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllByContext(context.Background(), model, by, orderBy, opts...)
+}
+
+// FindAllBy cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllByContext(ctx context.Context, model interface{}, by map[string]interface{}, orderBy string, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := g.query(ctx, opts).Where(by).Scopes(sorted(orderBy)).Find(model).Error
 	return model, err
 }
 
 // Find any records by using the row name and the data. Preload will get all associations in the model
 //
 //	Example:
-//	FindAllByWithPreload(&User, map[string]interface{}{}, "created_at desc") // will get all User
-//	FindAllByWithPreload(&User, map[string]interface{}{"name":"XXX"}, "created_at desc") // will get all User with name = "XXX"
-func (g *GormRepository) FindAllByWithPreload(model interface{}, by map[string]interface{}, orderBy string) (interface{}, error) {
-	err := g.Db.Preload(clause.Associations).Where(by).Order(orderBy).Unscoped().Find(&model).Error
+//	FindAllByWithPreload(&[]User{}, map[string]interface{}{}, "created_at desc") // will get all User
+//	FindAllByWithPreload(&[]User{}, map[string]interface{}{"name":"XXX"}, "created_at desc") // will get all User with name = "XXX"
+func (g *GormRepository) FindAllByWithPreload(model interface{}, by map[string]interface{}, orderBy string, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllByWithPreloadContext(context.Background(), model, by, orderBy, opts...)
+}
+
+// FindAllByWithPreload cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllByWithPreloadContext(ctx context.Context, model interface{}, by map[string]interface{}, orderBy string, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := preload(g.query(ctx, opts)).Where(by).Scopes(sorted(orderBy)).Find(model).Error
 	return model, err
 }
 
 // Find any records by using the row name and the data. Preload will get all associations in the model
 //
 //	Example:
-//	FindAllByWithPreloadNestedPreload(&User, map[string]interface{}{}, "created_at desc", "User.Role") // will get all User
-//	FindAllByWithPreloadNestedPreload(&User, map[string]interface{}{"name":"XXX"}, "created_at desc", "User.Role") // will get all User with name = "XXX"
-func (g *GormRepository) FindAllByWithNestedPreload(model interface{}, by map[string]interface{}, orderBy, nestedPreload string) (interface{}, error) {
-	err := g.Db.Preload(nestedPreload).Preload(clause.Associations).Where(by).Order(orderBy).Unscoped().Find(&model).Error
+//	FindAllByWithPreloadNestedPreload(&[]User{}, map[string]interface{}{}, "created_at desc", "User.Role") // will get all User
+//	FindAllByWithPreloadNestedPreload(&[]User{}, map[string]interface{}{"name":"XXX"}, "created_at desc", "User.Role") // will get all User with name = "XXX"
+func (g *GormRepository) FindAllByWithNestedPreload(model interface{}, by map[string]interface{}, orderBy, nestedPreload string, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllByWithNestedPreloadContext(context.Background(), model, by, orderBy, nestedPreload, opts...)
+}
+
+// FindAllByWithNestedPreload cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllByWithNestedPreloadContext(ctx context.Context, model interface{}, by map[string]interface{}, orderBy, nestedPreload string, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := preload(g.query(ctx, opts), nestedPreload).Where(by).Scopes(sorted(orderBy)).Find(model).Error
 	return model, err
 }
 
 // Find any records by using the row name and the data. This will limit the result to specific number.
 //
 //	Example:
-//	FindAllByWithPagination(&User, map[string]interface{}{}, 1, 10, "created_at desc") // will get the first 10 User in the column
-//	FindAllByWithPagination(&User, map[string]interface{}{}, 2, 10, "created_at desc") // will get the next 10 User in the column
-//	FindAllByWithPagination(&User, map[string]interface{}{"name":"XXX"}, 2, 10, "created_at desc") // will get the first 10 User in the column with name = "XXX"
-func (g *GormRepository) FindAllByWithPagination(model interface{}, by map[string]interface{}, page, itemPerPage int, orderBy string) (interface{}, error) {
-	err := g.Db.Where(by).Order(orderBy).Limit(itemPerPage).Offset(page).Find(&model).Error
+//	FindAllByWithPagination(&[]User{}, map[string]interface{}{}, 1, 10, "created_at desc") // will get the first 10 User in the column
+//	FindAllByWithPagination(&[]User{}, map[string]interface{}{}, 2, 10, "created_at desc") // will get the next 10 User in the column
+//	FindAllByWithPagination(&[]User{}, map[string]interface{}{"name":"XXX"}, 2, 10, "created_at desc") // will get the first 10 User in the column with name = "XXX"
+func (g *GormRepository) FindAllByWithPagination(model interface{}, by map[string]interface{}, page, itemPerPage int, orderBy string, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllByWithPaginationContext(context.Background(), model, by, page, itemPerPage, orderBy, opts...)
+}
+
+// FindAllByWithPagination cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllByWithPaginationContext(ctx context.Context, model interface{}, by map[string]interface{}, page, itemPerPage int, orderBy string, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := paginate(g.query(ctx, opts).Where(by).Scopes(sorted(orderBy)), page, itemPerPage).Find(model).Error
 	return model, err
 }
 
 // Find any records by using the row name and the data. This will limit the result to specific number and will get all associations in the model
 //
 //	Example:
-//	FindAllByWithPreloadAndPagination(&User, map[string]interface{}{}, 1, 10, "created_at desc") // will get the first 10 User in the column
-//	FindAllByWithPreloadAndPagination(&User, map[string]interface{}{}, 2, 10, "created_at desc") // will get the next 10 User in the column
-//	FindAllByWithPreloadAndPagination(&User, map[string]interface{}{"name":"XXX"}, 2, 10, "created_at desc") // will get the first 10 User in the column with name = "XXX"
-func (g *GormRepository) FindAllByWithPreloadAndPagination(model interface{}, by map[string]interface{}, page, itemPerPage int, orderBy string) (interface{}, error) {
-	err := g.Db.Preload(clause.Associations).Where(by).Order(orderBy).Limit(itemPerPage).Offset(page).Find(&model).Error
+//	FindAllByWithPreloadAndPagination(&[]User{}, map[string]interface{}{}, 1, 10, "created_at desc") // will get the first 10 User in the column
+//	FindAllByWithPreloadAndPagination(&[]User{}, map[string]interface{}{}, 2, 10, "created_at desc") // will get the next 10 User in the column
+//	FindAllByWithPreloadAndPagination(&[]User{}, map[string]interface{}{"name":"XXX"}, 2, 10, "created_at desc") // will get the first 10 User in the column with name = "XXX"
+func (g *GormRepository) FindAllByWithPreloadAndPagination(model interface{}, by map[string]interface{}, page, itemPerPage int, orderBy string, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllByWithPreloadAndPaginationContext(context.Background(), model, by, page, itemPerPage, orderBy, opts...)
+}
+
+// FindAllByWithPreloadAndPagination cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllByWithPreloadAndPaginationContext(ctx context.Context, model interface{}, by map[string]interface{}, page, itemPerPage int, orderBy string, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := paginate(preload(g.query(ctx, opts)).Where(by).Scopes(sorted(orderBy)), page, itemPerPage).Find(model).Error
 	return model, err
 }
 
 // Find any records by using the row name and the data. This will limit the result to specific number and will get all associations in the model
 //
 //	Example:
-//	FindAllByWithNestedPreloadAndPagination(&User, map[string]interface{}{}, 1, 10, "created_at desc", "User.Role") // will get the first 10 User in the column
-//	FindAllByWithNestedPreloadAndPagination(&User, map[string]interface{}{}, 2, 10, "created_at desc", "User.Role") // will get the next 10 User in the column
-//	FindAllByWithNestedPreloadAndPagination(&User, map[string]interface{}{"name":"XXX"}, 2, 10, "created_at desc", "User.Role") // will get the first 10 User in the column with name = "XXX"
-func (g *GormRepository) FindAllByWithNestedPreloadAndPagination(model interface{}, by map[string]interface{}, page, itemPerPage int, orderBy, nestedPreload string) (interface{}, error) {
-	err := g.Db.Preload(nestedPreload).Preload(clause.Associations).Where(by).Order(orderBy).Limit(itemPerPage).Offset(page).Find(&model).Error
+//	FindAllByWithNestedPreloadAndPagination(&[]User{}, map[string]interface{}{}, 1, 10, "created_at desc", "User.Role") // will get the first 10 User in the column
+//	FindAllByWithNestedPreloadAndPagination(&[]User{}, map[string]interface{}{}, 2, 10, "created_at desc", "User.Role") // will get the next 10 User in the column
+//	FindAllByWithNestedPreloadAndPagination(&[]User{}, map[string]interface{}{"name":"XXX"}, 2, 10, "created_at desc", "User.Role") // will get the first 10 User in the column with name = "XXX"
+func (g *GormRepository) FindAllByWithNestedPreloadAndPagination(model interface{}, by map[string]interface{}, page, itemPerPage int, orderBy, nestedPreload string, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllByWithNestedPreloadAndPaginationContext(context.Background(), model, by, page, itemPerPage, orderBy, nestedPreload, opts...)
+}
+
+// FindAllByWithNestedPreloadAndPagination cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllByWithNestedPreloadAndPaginationContext(ctx context.Context, model interface{}, by map[string]interface{}, page, itemPerPage int, orderBy, nestedPreload string, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := paginate(preload(g.query(ctx, opts), nestedPreload).Where(by).Scopes(sorted(orderBy)), page, itemPerPage).Find(model).Error
 	return model, err
 }
 
-// Find any records by using custom SQL Query.
+// Find any records by using custom SQL Query. The query is used as is, never build it from user input,
+// use FindAllUsingCustomQueryWithArgs or FindAllByFilter instead.
 //
 //	Example:
-//	FindAllUsingCustomQuery(&User, "name = 'XXX' AND email == 'YYY'", "created_at desc") // will get all User in the column with name = "XXX" and email = "YYY"
-func (g *GormRepository) FindAllUsingCustomQuery(model interface{}, query, orderBy string) (interface{}, error) {
-	err := g.Db.Where(query).Order(orderBy).Unscoped().Find(&model).Error
+//	FindAllUsingCustomQuery(&[]User{}, "name = 'XXX' AND email == 'YYY'", "created_at desc") // will get all User in the column with name = "XXX" and email = "YYY"
+func (g *GormRepository) FindAllUsingCustomQuery(model interface{}, query, orderBy string, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllUsingCustomQueryContext(context.Background(), model, query, orderBy, opts...)
+}
+
+// FindAllUsingCustomQuery cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllUsingCustomQueryContext(ctx context.Context, model interface{}, query, orderBy string, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := g.query(ctx, opts).Where(query).Scopes(sorted(orderBy)).Find(model).Error
 	return model, err
 }
 
 // Find any records by using custom SQL Query. Preload will get all associations in the model
 //
 //	Example:
-//	FindAllUsingCustomQueryWithPreload(&User, "name = 'XXX' AND email == 'YYY'", "created_at desc") // will get all User in the column with name = "XXX" and email = "YYY"
-func (g *GormRepository) FindAllUsingCustomQueryWithPreload(model interface{}, query, orderBy string) (interface{}, error) {
-	err := g.Db.Preload(clause.Associations).Where(query).Order(orderBy).Unscoped().Find(&model).Error
+//	FindAllUsingCustomQueryWithPreload(&[]User{}, "name = 'XXX' AND email == 'YYY'", "created_at desc") // will get all User in the column with name = "XXX" and email = "YYY"
+func (g *GormRepository) FindAllUsingCustomQueryWithPreload(model interface{}, query, orderBy string, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllUsingCustomQueryWithPreloadContext(context.Background(), model, query, orderBy, opts...)
+}
+
+// FindAllUsingCustomQueryWithPreload cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllUsingCustomQueryWithPreloadContext(ctx context.Context, model interface{}, query, orderBy string, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := preload(g.query(ctx, opts)).Where(query).Scopes(sorted(orderBy)).Find(model).Error
 	return model, err
 }
 
 // Find any records by using custom SQL Query. Preload will get all associations in the model
 //
 //	Example:
-//	FindAllUsingCustomQueryWithNestedPreload(&User, "name = 'XXX' AND email == 'YYY'", "created_at desc", "User.Role") // will get all User in the column with name = "XXX" and email = "YYY"
-func (g *GormRepository) FindAllUsingCustomQueryWithNestedPreload(model interface{}, query, orderBy, nestedPreload string) (interface{}, error) {
-	err := g.Db.Preload(nestedPreload).Preload(clause.Associations).Where(query).Order(orderBy).Unscoped().Find(&model).Error
+//	FindAllUsingCustomQueryWithNestedPreload(&[]User{}, "name = 'XXX' AND email == 'YYY'", "created_at desc", "User.Role") // will get all User in the column with name = "XXX" and email = "YYY"
+func (g *GormRepository) FindAllUsingCustomQueryWithNestedPreload(model interface{}, query, orderBy, nestedPreload string, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllUsingCustomQueryWithNestedPreloadContext(context.Background(), model, query, orderBy, nestedPreload, opts...)
+}
+
+// FindAllUsingCustomQueryWithNestedPreload cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllUsingCustomQueryWithNestedPreloadContext(ctx context.Context, model interface{}, query, orderBy, nestedPreload string, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := preload(g.query(ctx, opts), nestedPreload).Where(query).Scopes(sorted(orderBy)).Find(model).Error
 	return model, err
 }
 
 // Find any records by using any SQL Query. This will limit the result to specific number.
 //
 //	Example:
-//	FindAllUsingCustomQueryWithPagination(&User, "name = 'XXX' AND email = 'YYY'", "created_at desc", 1, 10) // will get the first 10 User in the column with name = "XXX" and email = "YYY"
-//	FindAllUsingCustomQueryWithPagination(&User, "name = 'XXX' AND email = 'YYY'", "created_at desc", 2, 10) // will get the next 10 User in the column with name = "XXX" and email = "YYY"
-func (g *GormRepository) FindAllUsingCustomQueryWithPagination(model interface{}, query, orderBy string, page, itemPerPage int) (interface{}, error) {
-	err := g.Db.Where(query).Order(orderBy).Limit(itemPerPage).Offset(page).Find(&model).Error
+//	FindAllUsingCustomQueryWithPagination(&[]User{}, "name = 'XXX' AND email = 'YYY'", "created_at desc", 1, 10) // will get the first 10 User in the column with name = "XXX" and email = "YYY"
+//	FindAllUsingCustomQueryWithPagination(&[]User{}, "name = 'XXX' AND email = 'YYY'", "created_at desc", 2, 10) // will get the next 10 User in the column with name = "XXX" and email = "YYY"
+func (g *GormRepository) FindAllUsingCustomQueryWithPagination(model interface{}, query, orderBy string, page, itemPerPage int, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllUsingCustomQueryWithPaginationContext(context.Background(), model, query, orderBy, page, itemPerPage, opts...)
+}
+
+// FindAllUsingCustomQueryWithPagination cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllUsingCustomQueryWithPaginationContext(ctx context.Context, model interface{}, query, orderBy string, page, itemPerPage int, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := paginate(g.query(ctx, opts).Where(query).Scopes(sorted(orderBy)), page, itemPerPage).Find(model).Error
 	return model, err
 }
 
 // Find any records by using any SQL Query. This will limit the result to specific number and will get all associations in the model
 //
 //	Example:
-//	FindAllUsingCustomQueryWithPreloadAndPagination(&User, "name = 'XXX' AND email = 'YYY'", "created_at desc", 1, 10) // will get the first 10 User in the column with name = "XXX" and email = "YYY"
-//	FindAllUsingCustomQueryWithPreloadAndPagination(&User, "name = 'XXX' AND email = 'YYY'", "created_at desc", 2, 10) // will get the next 10 User in the column with name = "XXX" and email = "YYY"
-func (g *GormRepository) FindAllUsingCustomQueryWithPreloadAndPagination(model interface{}, query, orderBy string, page, itemPerPage int) (interface{}, error) {
-	err := g.Db.Preload(clause.Associations).Where(query).Order(orderBy).Limit(itemPerPage).Offset(page).Find(&model).Error
+//	FindAllUsingCustomQueryWithPreloadAndPagination(&[]User{}, "name = 'XXX' AND email = 'YYY'", "created_at desc", 1, 10) // will get the first 10 User in the column with name = "XXX" and email = "YYY"
+//	FindAllUsingCustomQueryWithPreloadAndPagination(&[]User{}, "name = 'XXX' AND email = 'YYY'", "created_at desc", 2, 10) // will get the next 10 User in the column with name = "XXX" and email = "YYY"
+func (g *GormRepository) FindAllUsingCustomQueryWithPreloadAndPagination(model interface{}, query, orderBy string, page, itemPerPage int, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllUsingCustomQueryWithPreloadAndPaginationContext(context.Background(), model, query, orderBy, page, itemPerPage, opts...)
+}
+
+// FindAllUsingCustomQueryWithPreloadAndPagination cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllUsingCustomQueryWithPreloadAndPaginationContext(ctx context.Context, model interface{}, query, orderBy string, page, itemPerPage int, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := paginate(preload(g.query(ctx, opts)).Where(query).Scopes(sorted(orderBy)), page, itemPerPage).Find(model).Error
 	return model, err
 }
 
 // Find any records by using any SQL Query. This will limit the result to specific number and will get all associations in the model
 //
 //	Example:
-//	FindAllUsingCustomQueryWithPreloadAndPagination(&User, "name = 'XXX' AND email = 'YYY'", "created_at desc", 1, 10) // will get the first 10 User in the column with name = "XXX" and email = "YYY"
-//	FindAllUsingCustomQueryWithPreloadAndPagination(&User, "name = 'XXX' AND email = 'YYY'", "created_at desc", 2, 10) // will get the next 10 User in the column with name = "XXX" and email = "YYY"
-func (g *GormRepository) FindAllUsingCustomQueryWithNestedPreloadAndPagination(model interface{}, query, orderBy, nestedPreload string, page, itemPerPage int) (interface{}, error) {
-	err := g.Db.Preload(nestedPreload).Preload(clause.Associations).Where(query).Order(orderBy).Limit(itemPerPage).Offset(page).Find(&model).Error
+//	FindAllUsingCustomQueryWithPreloadAndPagination(&[]User{}, "name = 'XXX' AND email = 'YYY'", "created_at desc", 1, 10) // will get the first 10 User in the column with name = "XXX" and email = "YYY"
+//	FindAllUsingCustomQueryWithPreloadAndPagination(&[]User{}, "name = 'XXX' AND email = 'YYY'", "created_at desc", 2, 10) // will get the next 10 User in the column with name = "XXX" and email = "YYY"
+func (g *GormRepository) FindAllUsingCustomQueryWithNestedPreloadAndPagination(model interface{}, query, orderBy, nestedPreload string, page, itemPerPage int, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllUsingCustomQueryWithNestedPreloadAndPaginationContext(context.Background(), model, query, orderBy, nestedPreload, page, itemPerPage, opts...)
+}
+
+// FindAllUsingCustomQueryWithNestedPreloadAndPagination cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllUsingCustomQueryWithNestedPreloadAndPaginationContext(ctx context.Context, model interface{}, query, orderBy, nestedPreload string, page, itemPerPage int, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := paginate(preload(g.query(ctx, opts), nestedPreload).Where(query).Scopes(sorted(orderBy)), page, itemPerPage).Find(model).Error
+	return model, err
+}
+
+// Find a page of records by using the row name and the data, and count all of them. The first page is 1
+//
+//	Example:
+//	var users []User
+//	info, err := FindPageBy(&users, map[string]interface{}{"name":"XXX"}, 2, 10, "created_at desc") // will get the User 11 to 20 with name = "XXX"
+func (g *GormRepository) FindPageBy(model interface{}, by map[string]interface{}, page, itemPerPage int, orderBy string, opts ...QueryOption) (PageInfo, error) {
+	return g.FindPageByContext(context.Background(), model, by, page, itemPerPage, orderBy, opts...)
+}
+
+// FindPageBy cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindPageByContext(ctx context.Context, model interface{}, by map[string]interface{}, page, itemPerPage int, orderBy string, opts ...QueryOption) (PageInfo, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	return findPage(g.query(ctx, opts).Where(by), model, model, page, itemPerPage, orderBy, nil)
+}
+
+// Find a page of records by using custom SQL Query, and count all of them. The first page is 1
+//
+//	Example:
+//	var users []User
+//	info, err := FindPageUsingCustomQuery(&users, "name = 'XXX' AND email = 'YYY'", "created_at desc", 1, 10) // will get the first 10 User with name = "XXX" and email = "YYY"
+func (g *GormRepository) FindPageUsingCustomQuery(model interface{}, query, orderBy string, page, itemPerPage int, opts ...QueryOption) (PageInfo, error) {
+	return g.FindPageUsingCustomQueryContext(context.Background(), model, query, orderBy, page, itemPerPage, opts...)
+}
+
+// FindPageUsingCustomQuery cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindPageUsingCustomQueryContext(ctx context.Context, model interface{}, query, orderBy string, page, itemPerPage int, opts ...QueryOption) (PageInfo, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	return findPage(g.query(ctx, opts).Where(query), model, model, page, itemPerPage, orderBy, nil)
+}
+
+// Find the records after a cursor by using the row name and the data. Unlike the offset pagination, it stays fast
+// on large tables and never returns a record twice when records are inserted meanwhile.
+// The cursors returned in CursorInfo point at the next and previous pages, the first page has no cursor.
+//
+//	Example:
+//	var users []User
+//	sort := []SortKey{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}}
+//	info, err := FindAllByWithCursor(&users, map[string]interface{}{"name":"XXX"}, sort, c.Query("cursor"), 10) // will get 10 User with name = "XXX" after the cursor
+func (g *GormRepository) FindAllByWithCursor(model interface{}, by map[string]interface{}, sort []SortKey, cursor string, limit int, opts ...QueryOption) (CursorInfo, error) {
+	return g.FindAllByWithCursorContext(context.Background(), model, by, sort, cursor, limit, opts...)
+}
+
+// FindAllByWithCursor cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllByWithCursorContext(ctx context.Context, model interface{}, by map[string]interface{}, sort []SortKey, cursor string, limit int, opts ...QueryOption) (CursorInfo, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	return findCursorPage(g.query(ctx, opts).Where(by), model, sort, cursor, limit)
+}
+
+// Find a record matching the filter.
+//
+//	Example:
+//	FindByFilter(&User{}, Eq("email", email)) // will get result User with the email
+func (g *GormRepository) FindByFilter(model interface{}, filter Filter, opts ...QueryOption) (interface{}, error) {
+	return g.FindByFilterContext(context.Background(), model, filter, opts...)
+}
+
+// FindByFilter cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindByFilterContext(ctx context.Context, model interface{}, filter Filter, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := filter.apply(g.query(ctx, opts)).First(model).Error
+	return model, err
+}
+
+// Find any records matching the filter.
+//
+//	Example:
+//	FindAllByFilter(&[]User{}, And(Eq("role", "admin"), Like("name", "%"+search+"%")), "created_at desc") // will get all admin User with the search in their name
+func (g *GormRepository) FindAllByFilter(model interface{}, filter Filter, orderBy string, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllByFilterContext(context.Background(), model, filter, orderBy, opts...)
+}
+
+// FindAllByFilter cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllByFilterContext(ctx context.Context, model interface{}, filter Filter, orderBy string, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := filter.apply(g.query(ctx, opts)).Scopes(sorted(orderBy)).Find(model).Error
+	return model, err
+}
+
+// Find a page of records matching the filter, and count all of them. The first page is 1
+//
+//	Example:
+//	var users []User
+//	info, err := FindPageByFilter(&users, In("role", roles), 1, 10, "created_at desc") // will get the first 10 User having one of the roles
+func (g *GormRepository) FindPageByFilter(model interface{}, filter Filter, page, itemPerPage int, orderBy string, opts ...QueryOption) (PageInfo, error) {
+	return g.FindPageByFilterContext(context.Background(), model, filter, page, itemPerPage, orderBy, opts...)
+}
+
+// FindPageByFilter cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindPageByFilterContext(ctx context.Context, model interface{}, filter Filter, page, itemPerPage int, orderBy string, opts ...QueryOption) (PageInfo, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	return findPage(filter.apply(g.query(ctx, opts)), model, model, page, itemPerPage, orderBy, nil)
+}
+
+// Find any records by using custom SQL Query with bound arguments, so user input never ends up in the query itself.
+//
+//	Example:
+//	FindAllUsingCustomQueryWithArgs(&[]User{}, "name = ? AND email = ?", []interface{}{name, email}, "created_at desc") // will get all User in the column with the name and email
+func (g *GormRepository) FindAllUsingCustomQueryWithArgs(model interface{}, query string, args []interface{}, orderBy string, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllUsingCustomQueryWithArgsContext(context.Background(), model, query, args, orderBy, opts...)
+}
+
+// FindAllUsingCustomQueryWithArgs cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllUsingCustomQueryWithArgsContext(ctx context.Context, model interface{}, query string, args []interface{}, orderBy string, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := g.query(ctx, opts).Where(query, args...).Scopes(sorted(orderBy)).Find(model).Error
+	return model, err
+}
+
+// Find a page of records by using custom SQL Query with bound arguments, and count all of them. The first page is 1
+//
+//	Example:
+//	var users []User
+//	info, err := FindPageUsingCustomQueryWithArgs(&users, "name = ? AND email = ?", []interface{}{name, email}, "created_at desc", 1, 10)
+func (g *GormRepository) FindPageUsingCustomQueryWithArgs(model interface{}, query string, args []interface{}, orderBy string, page, itemPerPage int, opts ...QueryOption) (PageInfo, error) {
+	return g.FindPageUsingCustomQueryWithArgsContext(context.Background(), model, query, args, orderBy, page, itemPerPage, opts...)
+}
+
+// FindPageUsingCustomQueryWithArgs cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindPageUsingCustomQueryWithArgsContext(ctx context.Context, model interface{}, query string, args []interface{}, orderBy string, page, itemPerPage int, opts ...QueryOption) (PageInfo, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	return findPage(g.query(ctx, opts).Where(query, args...), model, model, page, itemPerPage, orderBy, nil)
+}
+
+// Find any records by using custom SQL Query with bound arguments. Preload will get all associations in the model
+//
+//	Example:
+//	FindAllUsingCustomQueryWithArgsAndPreload(&[]User{}, "name = ? AND email = ?", []interface{}{name, email}, "created_at desc")
+func (g *GormRepository) FindAllUsingCustomQueryWithArgsAndPreload(model interface{}, query string, args []interface{}, orderBy string, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllUsingCustomQueryWithArgsAndPreloadContext(context.Background(), model, query, args, orderBy, opts...)
+}
+
+// FindAllUsingCustomQueryWithArgsAndPreload cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllUsingCustomQueryWithArgsAndPreloadContext(ctx context.Context, model interface{}, query string, args []interface{}, orderBy string, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := preload(g.query(ctx, opts)).Where(query, args...).Scopes(sorted(orderBy)).Find(model).Error
 	return model, err
 }
+
+// Find any records by using custom SQL Query with bound arguments, and preload the nested associations
+//
+//	Example:
+//	FindAllUsingCustomQueryWithArgsAndNestedPreload(&[]User{}, "name = ?", []interface{}{name}, "created_at desc", "User.Role")
+func (g *GormRepository) FindAllUsingCustomQueryWithArgsAndNestedPreload(model interface{}, query string, args []interface{}, orderBy, nestedPreload string, opts ...QueryOption) (interface{}, error) {
+	return g.FindAllUsingCustomQueryWithArgsAndNestedPreloadContext(context.Background(), model, query, args, orderBy, nestedPreload, opts...)
+}
+
+// FindAllUsingCustomQueryWithArgsAndNestedPreload cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindAllUsingCustomQueryWithArgsAndNestedPreloadContext(ctx context.Context, model interface{}, query string, args []interface{}, orderBy, nestedPreload string, opts ...QueryOption) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	err := preload(g.query(ctx, opts), nestedPreload).Where(query, args...).Scopes(sorted(orderBy)).Find(model).Error
+	return model, err
+}
+
+// Find a page of records with all their associations by using custom SQL Query with bound arguments, and count all of them. The first page is 1
+//
+//	Example:
+//	var users []User
+//	info, err := FindPageUsingCustomQueryWithArgsAndPreload(&users, "name = ?", []interface{}{name}, "created_at desc", 1, 10)
+func (g *GormRepository) FindPageUsingCustomQueryWithArgsAndPreload(model interface{}, query string, args []interface{}, orderBy string, page, itemPerPage int, opts ...QueryOption) (PageInfo, error) {
+	return g.FindPageUsingCustomQueryWithArgsAndPreloadContext(context.Background(), model, query, args, orderBy, page, itemPerPage, opts...)
+}
+
+// FindPageUsingCustomQueryWithArgsAndPreload cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindPageUsingCustomQueryWithArgsAndPreloadContext(ctx context.Context, model interface{}, query string, args []interface{}, orderBy string, page, itemPerPage int, opts ...QueryOption) (PageInfo, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	return findPage(g.query(ctx, opts).Where(query, args...), model, model, page, itemPerPage, orderBy, func(db *gorm.DB) *gorm.DB { return preload(db) })
+}
+
+// Find a page of records with the nested associations by using custom SQL Query with bound arguments, and count all of them. The first page is 1
+//
+//	Example:
+//	var users []User
+//	info, err := FindPageUsingCustomQueryWithArgsAndNestedPreload(&users, "name = ?", []interface{}{name}, "created_at desc", "User.Role", 1, 10)
+func (g *GormRepository) FindPageUsingCustomQueryWithArgsAndNestedPreload(model interface{}, query string, args []interface{}, orderBy, nestedPreload string, page, itemPerPage int, opts ...QueryOption) (PageInfo, error) {
+	return g.FindPageUsingCustomQueryWithArgsAndNestedPreloadContext(context.Background(), model, query, args, orderBy, nestedPreload, page, itemPerPage, opts...)
+}
+
+// FindPageUsingCustomQueryWithArgsAndNestedPreload cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindPageUsingCustomQueryWithArgsAndNestedPreloadContext(ctx context.Context, model interface{}, query string, args []interface{}, orderBy, nestedPreload string, page, itemPerPage int, opts ...QueryOption) (PageInfo, error) {
+	ctx, cancel := withTimeout(ctx, g.Timeout)
+	defer cancel()
+	return findPage(g.query(ctx, opts).Where(query, args...), model, model, page, itemPerPage, orderBy, func(db *gorm.DB) *gorm.DB { return preload(db, nestedPreload) })
+}
+
+// Find the page of records of a list query bound from the query string, see BindListQuery
+//
+//	Example:
+//	var products []Product
+//	info, err := FindPageByListQuery(&products, query)
+func (g *GormRepository) FindPageByListQuery(model interface{}, query ListQuery, opts ...QueryOption) (PageInfo, error) {
+	return g.FindPageByListQueryContext(context.Background(), model, query, opts...)
+}
+
+// FindPageByListQuery cancelled with the context or after the timeout of the repository
+func (g *GormRepository) FindPageByListQueryContext(ctx context.Context, model interface{}, query ListQuery, opts ...QueryOption) (PageInfo, error) {
+	return g.FindPageByFilterContext(ctx, model, query.Filter, query.Page, query.PerPage, query.OrderBy(), opts...)
+}
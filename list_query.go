@@ -0,0 +1,226 @@
+package gobe
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultMaxPerPage = 100
+
+// The fields a list endpoint of a model accepts in its query string, anything else is rejected unless AllowUnknown is set
+//
+//	Example:
+//	var productList = ListSpec{
+//		Filters:     map[string]string{"status": "status", "price": "price", "category": "category_id"},
+//		Sorts:       map[string]string{"price": "price", "created_at": "created_at"},
+//		DefaultSort: "-created_at",
+//	}
+type ListSpec struct {
+	// Filterable fields, the name in the query string and its column
+	Filters map[string]string
+	// Sortable fields, the name in the query string and its column
+	Sorts map[string]string
+	// Sort used when the query string has none, written like the sort parameter, e.g. "-created_at,id"
+	DefaultSort string
+	// Items per page when per_page is missing. Defaults to 10
+	PerPage int
+	// Upper bound of per_page. Defaults to 100
+	MaxPerPage int
+	// Ignore the parameters the spec doesn't know instead of rejecting them, e.g. utm_source or a cache buster
+	AllowUnknown bool
+}
+
+// A list request bound from the query string, ready to be passed to the repository
+type ListQuery struct {
+	Filter  Filter
+	Sort    Sort
+	Page    int
+	PerPage int
+}
+
+// Get the ORDER BY clause of the sort keys
+func (q ListQuery) OrderBy() string {
+	return q.Sort.String()
+}
+
+var listParamPattern = regexp.MustCompile(`^([^\[\]]+)(?:\[(\w+)\])?$`)
+
+// Bind the query string of the request into a list query. The request is aborted with a 400 through BadRequestErrorWithMessage
+// when a parameter is invalid or not allowed by the spec, so the handler only has to return on error.
+//
+// The parameters are page, per_page, sort (e.g. sort=-created_at,name) and the filters, written as field=value
+// or field[operator]=value with the operators eq, ne, gt, gte, lt, lte, like, in (comma separated values) and null (true or false).
+// A repeated sort is read in order, e.g. sort=-price&sort=name. A repeated filter matches any of its values for eq and in,
+// e.g. status=active&status=draft, and every value for the other operators.
+//
+//	Example:
+//	// GET /products?status[in]=active,draft&price[gte]=10&sort=-price&page=2
+//	query, err := BindListQuery(c, productList)
+//	if err != nil {
+//		return
+//	}
+//	page, err := productRepo.FindAllByListQuery(c.Request.Context(), query)
+func BindListQuery(c *gin.Context, spec ListSpec) (ListQuery, error) {
+	query, err := ParseListQuery(c.Request.URL.Query(), spec)
+	if err != nil {
+		BadRequestErrorWithMessage(c, err.Error())
+	}
+	return query, err
+}
+
+// Parse query string values into a list query, see BindListQuery
+func ParseListQuery(values url.Values, spec ListSpec) (ListQuery, error) {
+	query := ListQuery{Page: 1, PerPage: spec.PerPage}
+	if query.PerPage < 1 {
+		query.PerPage = defaultItemPerPage
+	}
+	maxPerPage := spec.MaxPerPage
+	if maxPerPage < 1 {
+		maxPerPage = defaultMaxPerPage
+	}
+
+	var err error
+	if page := values.Get("page"); page != "" {
+		if query.Page, err = strconv.Atoi(page); err != nil || query.Page < 1 {
+			return ListQuery{}, fmt.Errorf("page must be a number greater than 0, got %q", page)
+		}
+	}
+	if perPage := values.Get("per_page"); perPage != "" {
+		if query.PerPage, err = strconv.Atoi(perPage); err != nil || query.PerPage < 1 || query.PerPage > maxPerPage {
+			return ListQuery{}, fmt.Errorf("per_page must be a number between 1 and %d, got %q", maxPerPage, perPage)
+		}
+	}
+	sortParam := strings.Join(values["sort"], ",")
+	if sortParam == "" {
+		sortParam = spec.DefaultSort
+	}
+	if query.Sort, err = parseListSort(sortParam, spec.Sorts); err != nil {
+		return ListQuery{}, err
+	}
+
+	params := make([]string, 0, len(values))
+	for param := range values {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+	var filters []Filter
+	for _, param := range params {
+		if param == "page" || param == "per_page" || param == "sort" {
+			continue
+		}
+		filter, err := parseListFilter(param, values[param], spec)
+		if err != nil {
+			return ListQuery{}, err
+		}
+		if filter.expr != nil || filter.err != nil {
+			filters = append(filters, filter)
+		}
+	}
+	query.Filter = And(filters...)
+	return query, query.Filter.err
+}
+
+func parseListSort(param string, sorts map[string]string) (Sort, error) {
+	if param == "" {
+		return nil, nil
+	}
+	var keys Sort
+	for _, field := range strings.Split(param, ",") {
+		if field == "" {
+			continue
+		}
+		key := SortKey{}
+		if strings.HasPrefix(field, "-") {
+			key.Desc = true
+			field = field[1:]
+		}
+		column, ok := sorts[field]
+		if !ok {
+			return nil, fmt.Errorf("cannot sort by %q", field)
+		}
+		if !columnPattern.MatchString(column) {
+			return nil, fmt.Errorf("invalid column name %q", column)
+		}
+		key.Column = column
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Get the filter of every value of a parameter, an empty filter when the spec doesn't know the parameter and allows unknown ones
+func parseListFilter(param string, values []string, spec ListSpec) (Filter, error) {
+	match := listParamPattern.FindStringSubmatch(param)
+	if match == nil {
+		if spec.AllowUnknown {
+			return Filter{}, nil
+		}
+		return Filter{}, fmt.Errorf("invalid filter %q", param)
+	}
+	field, op := match[1], match[2]
+	column, ok := spec.Filters[field]
+	if !ok {
+		if spec.AllowUnknown {
+			return Filter{}, nil
+		}
+		return Filter{}, fmt.Errorf("cannot filter by %q", field)
+	}
+
+	switch op {
+	case "", "eq", "in":
+		var in []string
+		for _, value := range values {
+			if op == "in" {
+				in = append(in, strings.Split(value, ",")...)
+			} else {
+				in = append(in, value)
+			}
+		}
+		if len(in) == 1 {
+			return Eq(column, in[0]), nil
+		}
+		return In(column, in), nil
+	}
+	filters := make([]Filter, len(values))
+	for i, value := range values {
+		filter, err := parseListOperator(field, column, op, value)
+		if err != nil {
+			return Filter{}, err
+		}
+		filters[i] = filter
+	}
+	return And(filters...), nil
+}
+
+func parseListOperator(field, column, op, value string) (Filter, error) {
+	switch op {
+	case "ne":
+		return Ne(column, value), nil
+	case "gt":
+		return Gt(column, value), nil
+	case "gte":
+		return Gte(column, value), nil
+	case "lt":
+		return Lt(column, value), nil
+	case "lte":
+		return Lte(column, value), nil
+	case "like":
+		return Like(column, value), nil
+	case "null":
+		isNull, err := strconv.ParseBool(value)
+		if err != nil {
+			return Filter{}, fmt.Errorf("%s[null] must be true or false, got %q", field, value)
+		}
+		if isNull {
+			return IsNull(column), nil
+		}
+		return IsNotNull(column), nil
+	default:
+		return Filter{}, fmt.Errorf("unknown operator %q of %q", op, field)
+	}
+}
@@ -0,0 +1,101 @@
+package gobe
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var filterItemList = ListSpec{
+	Filters:     map[string]string{"status": "status", "price": "price", "note": "note"},
+	Sorts:       map[string]string{"price": "price", "id": "id"},
+	DefaultSort: "id",
+}
+
+// Ignores utm_source, cache busters and the like
+var lenientList = ListSpec{Filters: filterItemList.Filters, Sorts: filterItemList.Sorts, DefaultSort: filterItemList.DefaultSort, AllowUnknown: true}
+
+func TestParseListQuery(t *testing.T) {
+	db := newTestDB(t, &filterItem{})
+	const from = "SELECT * FROM `filter_items` WHERE "
+
+	tests := []struct {
+		name  string
+		query string
+		spec  ListSpec
+		where string
+		order string
+	}{
+		{"default sort", "", filterItemList, "price > 0", "id"},
+		{"eq", "status=a", filterItemList, "`status` = \"a\" AND price > 0", "id"},
+		{"repeated eq", "status=a&status=b", filterItemList, "`status` IN (\"a\",\"b\") AND price > 0", "id"},
+		{"repeated in", "status[in]=a,b&status[in]=c", filterItemList, "`status` IN (\"a\",\"b\",\"c\") AND price > 0", "id"},
+		{"repeated gte", "price[gte]=1&price[gte]=5", filterItemList, "(`price` >= \"1\" AND `price` >= \"5\") AND price > 0", "id"},
+		{"operators", "price[lt]=9&note[null]=true", filterItemList, "(`note` IS NULL AND `price` < \"9\") AND price > 0", "id"},
+		{"repeated sort", "sort=-price&sort=id", filterItemList, "price > 0", "price DESC, id"},
+		{"unknown params", "utm_source=mail&_=1700000000&a[b][c]=d&status=a", lenientList, "`status` = \"a\" AND price > 0", "id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query, err := ParseListQuery(values, tt.spec)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got, want := filterSQL(t, db, query.Filter), from+tt.where; got != want {
+				t.Errorf("got  %s\nwant %s", got, want)
+			}
+			if got := query.OrderBy(); got != tt.order {
+				t.Errorf("order by %s, want %s", got, tt.order)
+			}
+		})
+	}
+}
+
+func TestParseListQueryErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		spec  ListSpec
+	}{
+		{"unknown operator", "price[foo]=1", filterItemList},
+		{"invalid null", "note[null]=maybe", filterItemList},
+		{"unknown sort", "sort=status", filterItemList},
+		{"invalid page", "page=0", filterItemList},
+		{"per_page over max", "per_page=1000", filterItemList},
+		{"unknown filter", "utm_source=mail", filterItemList},
+		{"invalid filter", "a[b][c]=d", filterItemList},
+		{"unknown operator allowing unknown params", "price[foo]=1", lenientList},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ParseListQuery(values, tt.spec); err == nil {
+				t.Fatalf("%s: got no error", tt.query)
+			}
+		})
+	}
+}
+
+func TestBindListQueryRejectsUnknownParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/items?status=a&utm_source=mail", nil)
+
+	if _, err := BindListQuery(c, ListSpec{Filters: map[string]string{"status": "status"}}); err == nil {
+		t.Fatal("got no error for an unknown param")
+	}
+	if w.Code != http.StatusBadRequest || !c.IsAborted() {
+		t.Errorf("status %d, aborted %t, want an aborted 400", w.Code, c.IsAborted())
+	}
+}
@@ -2,8 +2,10 @@ package gobe
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
@@ -15,32 +17,130 @@ type MongoConnector struct {
 }
 
 type MongoBaseConfig struct {
-	DBName     string `mapstructure:"db_name" json:"db_name"`
-	DBHost     string `mapstructure:"db_host" json:"db_host"`
-	DBPort     string `mapstructure:"db_port" json:"db_port"`
-	DBUsername string `mapstructure:"db_username" json:"db_username"`
-	DBPassword string `mapstructure:"db_password" json:"db_password"`
-	URI        string `mapstructure:"uri" json:"uri"`
+	DBName     string          `mapstructure:"db_name" json:"db_name"`
+	DBHost     string          `mapstructure:"db_host" json:"db_host"`
+	DBPort     string          `mapstructure:"db_port" json:"db_port"`
+	DBUsername string          `mapstructure:"db_username" json:"db_username"`
+	DBPassword Secret          `mapstructure:"db_password" json:"db_password"`
+	URI        Secret          `mapstructure:"uri" json:"uri" secret:"uri"`
+	Pool       mongoPoolConfig `mapstructure:"pool" json:"pool"`
+	Retry      retryConfig     `mapstructure:"retry" json:"retry"`
 }
 
-// Initialize new connection to MongoDB
-func NewMongoConfig(config *MongoBaseConfig) MongoConnector {
-	uri := config.URI
+// Settings of the connection pool, zero values keep the driver defaults
+type mongoPoolConfig struct {
+	// Maximum number of connections per server, defaults to 100
+	MaxPoolSize uint64 `mapstructure:"max_pool_size" json:"max_pool_size"`
+	// Minimum number of connections per server kept open
+	MinPoolSize uint64 `mapstructure:"min_pool_size" json:"min_pool_size"`
+	// Maximum number of connections being established at the same time, defaults to 2
+	MaxConnecting uint64 `mapstructure:"max_connecting" json:"max_connecting"`
+	// Amount of time after which an idle connection is closed, e.g. "5m"
+	MaxConnIdleTime time.Duration `mapstructure:"max_conn_idle_time" json:"max_conn_idle_time"`
+}
+
+func (p mongoPoolConfig) validate(prefix string) ValidationErrors {
+	var errs ValidationErrors
+	if p.MaxPoolSize > 0 && p.MinPoolSize > p.MaxPoolSize {
+		errs.add(prefix, "min_pool_size", "must not be greater than max_pool_size (%d), got %d", p.MaxPoolSize, p.MinPoolSize)
+	}
+	if p.MaxConnIdleTime < 0 {
+		errs.add(prefix, "max_conn_idle_time", "must not be negative, got %s", p.MaxConnIdleTime)
+	}
+	return errs
+}
+
+func (p mongoPoolConfig) apply(client *options.ClientOptions) {
+	if p.MaxPoolSize != 0 {
+		client.SetMaxPoolSize(p.MaxPoolSize)
+	}
+	if p.MinPoolSize != 0 {
+		client.SetMinPoolSize(p.MinPoolSize)
+	}
+	if p.MaxConnecting != 0 {
+		client.SetMaxConnecting(p.MaxConnecting)
+	}
+	if p.MaxConnIdleTime != 0 {
+		client.SetMaxConnIdleTime(p.MaxConnIdleTime)
+	}
+}
+
+// Check the config and report all the problems at once
+func (c *MongoBaseConfig) Validate() error {
+	return c.validate("").err()
+}
+
+func (c *MongoBaseConfig) validate(prefix string) ValidationErrors {
+	var errs ValidationErrors
+	errs.required(prefix, "db_name", c.DBName)
+	errs = append(errs, c.Pool.validate(prefix+"pool.")...)
+	errs = append(errs, c.Retry.validate(prefix+"retry.")...)
+	if c.URI != "" {
+		errs.uri(prefix, "uri", c.URI.Value(), "mongodb", "mongodb+srv")
+		return errs
+	}
+	errs.required(prefix, "db_host", c.DBHost)
+	errs.port(prefix, "db_port", c.DBPort, true)
+	return errs
+}
+
+// Initialize new connection to MongoDB and make sure the server is reachable
+func NewMongoConnector(config *MongoBaseConfig) (MongoConnector, error) {
+	uri := config.URI.Value()
 	if uri == "" {
-		uri = fmt.Sprintf("mongodb://%s:%s@%s:%s", config.DBUsername, config.DBPassword, config.DBHost, config.DBPort)
+		uri = fmt.Sprintf("mongodb://%s:%s@%s:%s", config.DBUsername, config.DBPassword.Value(), config.DBHost, config.DBPort)
 	}
 	client := options.Client()
 	client.ApplyURI(uri)
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
-	mongoClient, err := mongo.Connect(ctx, client)
+	config.Pool.apply(client)
+	// A malformed URI would otherwise be reported by mongo.Connect and retried as a dial failure
+	if err := client.Validate(); err != nil {
+		return MongoConnector{}, fmt.Errorf("%w: uri: %s", ErrInvalidConfig, err.Error())
+	}
+	var mongoClient *mongo.Client
+	err := config.Retry.do("mongo", func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+		res, err := mongo.Connect(ctx, client)
+		if err != nil {
+			return newMongoConnectionError(err)
+		}
+		if err = res.Ping(ctx, nil); err != nil {
+			res.Disconnect(context.Background())
+			return newMongoConnectionError(err)
+		}
+		mongoClient = res
+		return nil
+	})
 	if err != nil {
-		log.Println(err.Error())
-	} else {
-		log.Println("Connected!")
+		return MongoConnector{}, err
 	}
 
 	db := mongoClient.Database(config.DBName)
-	return MongoConnector{db}
+	return MongoConnector{db}, nil
+}
 
+// Initialize new connection to MongoDB
+//
+// It exits the process when the connection cannot be initialized, use NewMongoConnector to handle the error instead.
+func NewMongoConfig(config *MongoBaseConfig) MongoConnector {
+	conn, err := NewMongoConnector(config)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	log.Println("Connected!")
+	return conn
+}
+
+// Wrap a driver error into ConnectionError, telling authentication failures apart from the rest
+func newMongoConnectionError(err error) error {
+	kind := ErrDialFailure
+	var serverErr mongo.ServerError
+	// AuthenticationFailed
+	if errors.As(err, &serverErr) && serverErr.HasErrorCode(18) {
+		kind = ErrAuthFailure
+	} else if strings.Contains(err.Error(), "auth error") {
+		kind = ErrAuthFailure
+	}
+	return &ConnectionError{Kind: kind, Target: "mongo", Err: err}
 }
@@ -0,0 +1,74 @@
+package gobe
+
+import "gorm.io/gorm"
+
+// Number of items per page when it is not set
+const defaultItemPerPage = 10
+
+// Position of a page in the whole result. Page starts at 1
+type PageInfo struct {
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	TotalItems int64 `json:"total_items"`
+	TotalPages int   `json:"total_pages"`
+	HasNext    bool  `json:"has_next"`
+	HasPrev    bool  `json:"has_prev"`
+}
+
+// A page of records and its position in the whole result
+type Page[T any] struct {
+	Items []T `json:"items"`
+	PageInfo
+}
+
+// Compute the page metadata. A page lower than 1 is the first page, and itemPerPage defaults to 10
+//
+//	Example:
+//	NewPageInfo(2, 10, 25) // page 2 of 3, has next and previous pages
+func NewPageInfo(page, itemPerPage int, totalItems int64) PageInfo {
+	page, itemPerPage = normalizePage(page, itemPerPage)
+	totalPages := int((totalItems + int64(itemPerPage) - 1) / int64(itemPerPage))
+	return PageInfo{
+		Page:       page,
+		PerPage:    itemPerPage,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+		HasPrev:    page > 1,
+	}
+}
+
+func normalizePage(page, itemPerPage int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if itemPerPage < 1 {
+		itemPerPage = defaultItemPerPage
+	}
+	return page, itemPerPage
+}
+
+// Limit the query to a page of records, the first page is 1
+func paginate(db *gorm.DB, page, itemPerPage int) *gorm.DB {
+	page, itemPerPage = normalizePage(page, itemPerPage)
+	return db.Limit(itemPerPage).Offset((page - 1) * itemPerPage)
+}
+
+// Count the records matching the query then find a page of them into dest.
+// The associations are loaded by load, after counting
+func findPage(db *gorm.DB, model, dest interface{}, page, itemPerPage int, orderBy string, load func(*gorm.DB) *gorm.DB) (PageInfo, error) {
+	// The query is shared by the count and the find
+	db = db.Session(&gorm.Session{})
+	var totalItems int64
+	if err := db.Model(model).Count(&totalItems).Error; err != nil {
+		return PageInfo{}, err
+	}
+	info := NewPageInfo(page, itemPerPage, totalItems)
+	if load != nil {
+		db = load(db)
+	}
+	if err := paginate(db.Scopes(sorted(orderBy)), info.Page, info.PerPage).Find(dest).Error; err != nil {
+		return PageInfo{}, err
+	}
+	return info, nil
+}
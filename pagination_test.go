@@ -0,0 +1,28 @@
+package gobe
+
+import "testing"
+
+func TestNewPageInfo(t *testing.T) {
+	tests := []struct {
+		name        string
+		page        int
+		itemPerPage int
+		totalItems  int64
+		want        PageInfo
+	}{
+		{"first page", 1, 10, 25, PageInfo{Page: 1, PerPage: 10, TotalItems: 25, TotalPages: 3, HasNext: true}},
+		{"last partial page", 3, 10, 25, PageInfo{Page: 3, PerPage: 10, TotalItems: 25, TotalPages: 3, HasPrev: true}},
+		{"full last page", 2, 10, 20, PageInfo{Page: 2, PerPage: 10, TotalItems: 20, TotalPages: 2, HasPrev: true}},
+		{"past the end", 5, 10, 25, PageInfo{Page: 5, PerPage: 10, TotalItems: 25, TotalPages: 3, HasPrev: true}},
+		{"no items", 1, 10, 0, PageInfo{Page: 1, PerPage: 10}},
+		{"page lower than 1", 0, 10, 25, PageInfo{Page: 1, PerPage: 10, TotalItems: 25, TotalPages: 3, HasNext: true}},
+		{"default item per page", 1, 0, 25, PageInfo{Page: 1, PerPage: 10, TotalItems: 25, TotalPages: 3, HasNext: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewPageInfo(tt.page, tt.itemPerPage, tt.totalItems); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,26 +1,123 @@
 package gobe
 
-import "github.com/go-redis/redis/v8"
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
 
 type RedisBaseConfig struct {
-	Host     string `mapstructure:"host" json:"host"`
-	Port     string `mapstructure:"port" json:"port"`
-	Username string `mapstructure:"username" json:"username"`
-	Password string `mapstructure:"password" json:"password"`
-	DB       int    `mapstructure:"db" json:"db"`
+	Host     string          `mapstructure:"host" json:"host"`
+	Port     string          `mapstructure:"port" json:"port"`
+	Username string          `mapstructure:"username" json:"username"`
+	Password Secret          `mapstructure:"password" json:"password"`
+	DB       int             `mapstructure:"db" json:"db"`
+	Pool     redisPoolConfig `mapstructure:"pool" json:"pool"`
+	Retry    retryConfig     `mapstructure:"retry" json:"retry"`
+}
+
+// Settings of the connection pool, zero values keep the go-redis defaults
+type redisPoolConfig struct {
+	// Maximum number of socket connections, defaults to 10 per CPU
+	PoolSize int `mapstructure:"pool_size" json:"pool_size"`
+	// Minimum number of idle connections kept open
+	MinIdleConns int `mapstructure:"min_idle_conns" json:"min_idle_conns"`
+	// Maximum amount of time a connection may be reused, e.g. "30m"
+	MaxConnAge time.Duration `mapstructure:"max_conn_age" json:"max_conn_age"`
+	// Amount of time to wait for a free connection when all of them are busy, e.g. "4s"
+	PoolTimeout time.Duration `mapstructure:"pool_timeout" json:"pool_timeout"`
+	// Amount of time after which an idle connection is closed, e.g. "5m"
+	IdleTimeout time.Duration `mapstructure:"idle_timeout" json:"idle_timeout"`
+}
+
+func (p redisPoolConfig) validate(prefix string) ValidationErrors {
+	var errs ValidationErrors
+	if p.PoolSize < 0 {
+		errs.add(prefix, "pool_size", "must not be negative, got %d", p.PoolSize)
+	}
+	if p.MinIdleConns < 0 {
+		errs.add(prefix, "min_idle_conns", "must not be negative, got %d", p.MinIdleConns)
+	} else if p.PoolSize > 0 && p.MinIdleConns > p.PoolSize {
+		errs.add(prefix, "min_idle_conns", "must not be greater than pool_size (%d), got %d", p.PoolSize, p.MinIdleConns)
+	}
+	if p.MaxConnAge < 0 {
+		errs.add(prefix, "max_conn_age", "must not be negative, got %s", p.MaxConnAge)
+	}
+	if p.PoolTimeout < 0 {
+		errs.add(prefix, "pool_timeout", "must not be negative, got %s", p.PoolTimeout)
+	}
+	return errs
 }
 
 type RedisClient struct {
 	*redis.Client
 }
 
+// Check the config and report all the problems at once
+func (c *RedisBaseConfig) Validate() error {
+	return c.validate("").err()
+}
+
+func (c *RedisBaseConfig) validate(prefix string) ValidationErrors {
+	var errs ValidationErrors
+	errs.required(prefix, "host", c.Host)
+	errs.port(prefix, "port", c.Port, true)
+	if c.DB < 0 {
+		errs.add(prefix, "db", "must not be negative, got %d", c.DB)
+	}
+	errs = append(errs, c.Pool.validate(prefix+"pool.")...)
+	errs = append(errs, c.Retry.validate(prefix+"retry.")...)
+	return errs
+}
+
+// Initialize new Redis client and make sure the server is reachable
+func NewRedisConnector(baseConfig *RedisBaseConfig) (RedisClient, error) {
+	client := newRedisClient(baseConfig)
+	err := baseConfig.Retry.do("redis", func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+		if err := client.Ping(ctx).Err(); err != nil {
+			return newRedisConnectionError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		client.Close()
+		return RedisClient{}, err
+	}
+	return RedisClient{client}, nil
+}
+
 // Initialize new Redis client
+//
+// The client connects lazily, use NewRedisConnector to check the connection up front.
 func NewRedisClient(baseConfig *RedisBaseConfig) RedisClient {
-	client := redis.NewClient(&redis.Options{
+	return RedisClient{newRedisClient(baseConfig)}
+}
+
+func newRedisClient(baseConfig *RedisBaseConfig) *redis.Client {
+	return redis.NewClient(&redis.Options{
 		Addr:     baseConfig.Host + ":" + baseConfig.Port,
 		Username: baseConfig.Username,
-		Password: baseConfig.Password,
+		Password: baseConfig.Password.Value(),
 		DB:       baseConfig.DB,
+
+		PoolSize:     baseConfig.Pool.PoolSize,
+		MinIdleConns: baseConfig.Pool.MinIdleConns,
+		MaxConnAge:   baseConfig.Pool.MaxConnAge,
+		PoolTimeout:  baseConfig.Pool.PoolTimeout,
+		IdleTimeout:  baseConfig.Pool.IdleTimeout,
 	})
-	return RedisClient{client}
+}
+
+// Wrap a client error into ConnectionError, telling authentication failures apart from the rest
+func newRedisConnectionError(err error) error {
+	kind := ErrDialFailure
+	msg := err.Error()
+	if strings.HasPrefix(msg, "WRONGPASS") || strings.HasPrefix(msg, "NOAUTH") || strings.Contains(msg, "invalid password") {
+		kind = ErrAuthFailure
+	}
+	return &ConnectionError{Kind: kind, Target: "redis", Err: err}
 }
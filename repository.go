@@ -0,0 +1,362 @@
+package gobe
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Initiate a type-safe GORM repository of the model T
+//
+//	Example:
+//	type UserRepository struct {
+//		gobe.Repository[User]
+//	}
+//
+//	repo := UserRepository{gobe.Repository[User]{Db: gormConn.DB}}
+//	user, err := repo.FindBy(ctx, map[string]interface{}{"id": 1}) // user is a *User
+type Repository[T any] struct {
+	Db *gorm.DB
+	// Default timeout of every query, e.g. from the query_timeout of SqlBaseConfig. No timeout when zero
+	Timeout time.Duration
+	// Record every Create, UpdateBy, DeleteBy, Restore and ForceDelete in the audit_logs table, see AuditLog
+	Audit bool
+}
+
+// Initiate a type-safe repository on the connection, every query is bounded by the query_timeout of the config
+//
+//	Example:
+//	repo := gobe.NewRepository[User](gormConn)
+func NewRepository[T any](conn GormConnector) *Repository[T] {
+	return &Repository[T]{Db: conn.DB, Timeout: conn.QueryTimeout}
+}
+
+func (r *Repository[T]) query(ctx context.Context, opts []QueryOption) *gorm.DB {
+	return withOptions(connection(ctx, r.Db), opts)
+}
+
+// Get a copy of the repository running its queries in the transaction, see GormRepository.WithTx
+func (r *Repository[T]) WithTx(tx *gorm.DB) *Repository[T] {
+	return &Repository[T]{Db: tx, Timeout: r.Timeout, Audit: r.Audit}
+}
+
+// Create/insert a new record to the table
+//
+// The drivers that cannot read a generated primary key back (e.g. ClickHouse) return ErrUnsupportedFeature when it is left empty.
+func (r *Repository[T]) Create(ctx context.Context, model *T) error {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	if err := checkGeneratedKey(r.Db, model); err != nil {
+		return err
+	}
+	return auditedWrite(ctx, r.Db, r.Audit, model, nil, AuditCreate, func(db *gorm.DB) error {
+		return db.Create(model).Error
+	})
+}
+
+// Update a value in a record. A versioned model returns ErrStaleObject when the version of the values is not the one of the record, see GormRepository.UpdateBy
+//
+//	Example:
+//	UpdateBy(ctx, map[string]interface{}{"id":1}, map[string]interface{}{"name":"YYY"}) // will update the record name with ID = 1 to "YYY"
+func (r *Repository[T]) UpdateBy(ctx context.Context, by map[string]interface{}, value map[string]interface{}) error {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return auditedWrite(ctx, r.Db, r.Audit, new(T), by, AuditUpdate, func(db *gorm.DB) error {
+		return updateBy(db, new(T), by, value)
+	})
+}
+
+// Delete a record. A model with a gorm.DeletedAt field is soft deleted, see Restore and ForceDelete
+//
+//	Example:
+//	DeleteBy(ctx, map[string]interface{}{"id":1}) // will delete the record with ID = 1
+func (r *Repository[T]) DeleteBy(ctx context.Context, by map[string]interface{}) error {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return auditedWrite(ctx, r.Db, r.Audit, new(T), by, AuditDelete, func(db *gorm.DB) error {
+		return db.Where(by).Delete(new(T)).Error
+	})
+}
+
+// Restore the soft deleted records matching the conditions. T must have a gorm.DeletedAt field
+func (r *Repository[T]) Restore(ctx context.Context, by map[string]interface{}) error {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return auditedWrite(ctx, r.Db, r.Audit, new(T), by, AuditUpdate, func(db *gorm.DB) error {
+		return restore(db, new(T), by)
+	})
+}
+
+// Delete the records matching the conditions for good, soft deleted or not
+func (r *Repository[T]) ForceDelete(ctx context.Context, by map[string]interface{}) error {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return auditedWrite(ctx, r.Db, r.Audit, new(T), by, AuditDelete, func(db *gorm.DB) error {
+		return db.Unscoped().Where(by).Delete(new(T)).Error
+	})
+}
+
+// Find the audit log entries of a record, the oldest first, see GormRepository.History
+func (r *Repository[T]) History(ctx context.Context, id interface{}) ([]AuditLog, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return auditHistory(connection(ctx, r.Db), new(T), id)
+}
+
+// Insert the records, batchSize records per statement, in a transaction. Returns the number of inserted records, see GormRepository.CreateInBatches
+func (r *Repository[T]) CreateInBatches(ctx context.Context, items []T, batchSize int) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return createInBatches(ctx, r.Db, &items, batchSize)
+}
+
+// Insert the records, updating the ones conflicting on the conflict columns instead. Returns the number of inserted and updated records, see GormRepository.Upsert
+//
+//	Example:
+//	Upsert(ctx, products, []string{"sku"}, []string{"name", "price"})
+func (r *Repository[T]) Upsert(ctx context.Context, items []T, conflictColumns, updateColumns []string) (UpsertResult, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return upsert(ctx, r.Db, &items, conflictColumns, updateColumns)
+}
+
+// Find a record by using the row name and the data. It returns gorm.ErrRecordNotFound when nothing matches
+//
+//	Example:
+//	FindBy(ctx, map[string]interface{}{"id":1}) // will get the record with ID = 1
+func (r *Repository[T]) FindBy(ctx context.Context, by map[string]interface{}, opts ...QueryOption) (*T, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return first[T](r.query(ctx, opts).Where(by))
+}
+
+// Find a record by using the row name and the data. Preload will get all associations in the model
+func (r *Repository[T]) FindByWithPreload(ctx context.Context, by map[string]interface{}, opts ...QueryOption) (*T, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return first[T](preload(r.query(ctx, opts)).Where(by))
+}
+
+// Find a record by using the row name and the data. Preload will get all associations in the model
+//
+//	Example:
+//	FindByWithNestedPreload(ctx, map[string]interface{}{"id":1}, "User.Role") // will get the record with ID = 1
+func (r *Repository[T]) FindByWithNestedPreload(ctx context.Context, by map[string]interface{}, nestedPreload string, opts ...QueryOption) (*T, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return first[T](preload(r.query(ctx, opts), nestedPreload).Where(by))
+}
+
+// Find any records by using the row name and the data.
+//
+//	Example:
+//	FindAllBy(ctx, map[string]interface{}{}, "created_at desc") // will get all records
+//	FindAllBy(ctx, map[string]interface{}{"name":"XXX"}, "created_at desc") // will get all records with name = "XXX"
+func (r *Repository[T]) FindAllBy(ctx context.Context, by map[string]interface{}, orderBy string, opts ...QueryOption) ([]T, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return find[T](r.query(ctx, opts).Where(by).Scopes(sorted(orderBy)))
+}
+
+// Find any records by using the row name and the data. Preload will get all associations in the model
+func (r *Repository[T]) FindAllByWithPreload(ctx context.Context, by map[string]interface{}, orderBy string, opts ...QueryOption) ([]T, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return find[T](preload(r.query(ctx, opts)).Where(by).Scopes(sorted(orderBy)))
+}
+
+// Find any records by using the row name and the data. Preload will get all associations in the model
+func (r *Repository[T]) FindAllByWithNestedPreload(ctx context.Context, by map[string]interface{}, orderBy, nestedPreload string, opts ...QueryOption) ([]T, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return find[T](preload(r.query(ctx, opts), nestedPreload).Where(by).Scopes(sorted(orderBy)))
+}
+
+// Find any records by using the row name and the data. This will get a page of records and count all of them, the first page is 1.
+//
+//	Example:
+//	FindAllByWithPagination(ctx, map[string]interface{}{}, 1, 10, "created_at desc") // will get the first 10 records
+func (r *Repository[T]) FindAllByWithPagination(ctx context.Context, by map[string]interface{}, page, itemPerPage int, orderBy string, opts ...QueryOption) (Page[T], error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return findPageOf[T](r.query(ctx, opts).Where(by), page, itemPerPage, orderBy, nil)
+}
+
+// Find any records by using the row name and the data. This will get a page of records with all associations in the model and count all of them, the first page is 1
+func (r *Repository[T]) FindAllByWithPreloadAndPagination(ctx context.Context, by map[string]interface{}, page, itemPerPage int, orderBy string, opts ...QueryOption) (Page[T], error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return findPageOf[T](r.query(ctx, opts).Where(by), page, itemPerPage, orderBy, func(db *gorm.DB) *gorm.DB { return preload(db) })
+}
+
+// Find any records by using the row name and the data. This will get a page of records with all associations in the model and count all of them, the first page is 1
+func (r *Repository[T]) FindAllByWithNestedPreloadAndPagination(ctx context.Context, by map[string]interface{}, page, itemPerPage int, orderBy, nestedPreload string, opts ...QueryOption) (Page[T], error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return findPageOf[T](r.query(ctx, opts).Where(by), page, itemPerPage, orderBy, func(db *gorm.DB) *gorm.DB { return preload(db, nestedPreload) })
+}
+
+// Find any records by using custom SQL Query. The query is used as is, never build it from user input,
+// use FindAllUsingCustomQueryWithArgs or FindAllByFilter instead.
+//
+//	Example:
+//	FindAllUsingCustomQuery(ctx, "name = 'XXX' AND email = 'YYY'", "created_at desc") // will get all records with name = "XXX" and email = "YYY"
+func (r *Repository[T]) FindAllUsingCustomQuery(ctx context.Context, query, orderBy string, opts ...QueryOption) ([]T, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return find[T](r.query(ctx, opts).Where(query).Scopes(sorted(orderBy)))
+}
+
+// Find any records by using custom SQL Query. Preload will get all associations in the model
+func (r *Repository[T]) FindAllUsingCustomQueryWithPreload(ctx context.Context, query, orderBy string, opts ...QueryOption) ([]T, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return find[T](preload(r.query(ctx, opts)).Where(query).Scopes(sorted(orderBy)))
+}
+
+// Find any records by using custom SQL Query. Preload will get all associations in the model
+func (r *Repository[T]) FindAllUsingCustomQueryWithNestedPreload(ctx context.Context, query, orderBy, nestedPreload string, opts ...QueryOption) ([]T, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return find[T](preload(r.query(ctx, opts), nestedPreload).Where(query).Scopes(sorted(orderBy)))
+}
+
+// Find any records by using any SQL Query. This will get a page of records and count all of them, the first page is 1.
+func (r *Repository[T]) FindAllUsingCustomQueryWithPagination(ctx context.Context, query, orderBy string, page, itemPerPage int, opts ...QueryOption) (Page[T], error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return findPageOf[T](r.query(ctx, opts).Where(query), page, itemPerPage, orderBy, nil)
+}
+
+// Find any records by using any SQL Query. This will get a page of records with all associations in the model and count all of them, the first page is 1
+func (r *Repository[T]) FindAllUsingCustomQueryWithPreloadAndPagination(ctx context.Context, query, orderBy string, page, itemPerPage int, opts ...QueryOption) (Page[T], error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return findPageOf[T](r.query(ctx, opts).Where(query), page, itemPerPage, orderBy, func(db *gorm.DB) *gorm.DB { return preload(db) })
+}
+
+// Find any records by using any SQL Query. This will get a page of records with all associations in the model and count all of them, the first page is 1
+func (r *Repository[T]) FindAllUsingCustomQueryWithNestedPreloadAndPagination(ctx context.Context, query, orderBy, nestedPreload string, page, itemPerPage int, opts ...QueryOption) (Page[T], error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return findPageOf[T](r.query(ctx, opts).Where(query), page, itemPerPage, orderBy, func(db *gorm.DB) *gorm.DB { return preload(db, nestedPreload) })
+}
+
+// Find the records after a cursor by using the row name and the data. The first page has no cursor
+//
+//	Example:
+//	sort := []SortKey{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}}
+//	FindAllByWithCursor(ctx, map[string]interface{}{}, sort, c.Query("cursor"), 10) // will get 10 records after the cursor
+func (r *Repository[T]) FindAllByWithCursor(ctx context.Context, by map[string]interface{}, sort []SortKey, cursor string, limit int, opts ...QueryOption) (CursorPage[T], error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	items := []T{}
+	info, err := findCursorPage(r.query(ctx, opts).Where(by), &items, sort, cursor, limit)
+	if err != nil {
+		return CursorPage[T]{}, err
+	}
+	return CursorPage[T]{Items: items, CursorInfo: info}, nil
+}
+
+// Find a record matching the filter. It returns gorm.ErrRecordNotFound when nothing matches
+//
+//	Example:
+//	FindByFilter(ctx, Eq("email", email)) // will get the record with the email
+func (r *Repository[T]) FindByFilter(ctx context.Context, filter Filter, opts ...QueryOption) (*T, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return first[T](filter.apply(r.query(ctx, opts)))
+}
+
+// Find any records matching the filter.
+//
+//	Example:
+//	FindAllByFilter(ctx, And(Eq("role", "admin"), Like("name", "%"+search+"%")), "created_at desc") // will get all admin records with the search in their name
+func (r *Repository[T]) FindAllByFilter(ctx context.Context, filter Filter, orderBy string, opts ...QueryOption) ([]T, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return find[T](filter.apply(r.query(ctx, opts)).Scopes(sorted(orderBy)))
+}
+
+// Find a page of records matching the filter, and count all of them. The first page is 1
+func (r *Repository[T]) FindAllByFilterWithPagination(ctx context.Context, filter Filter, page, itemPerPage int, orderBy string, opts ...QueryOption) (Page[T], error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return findPageOf[T](filter.apply(r.query(ctx, opts)), page, itemPerPage, orderBy, nil)
+}
+
+// Find the page of records of a list query bound from the query string, see BindListQuery
+func (r *Repository[T]) FindAllByListQuery(ctx context.Context, query ListQuery, opts ...QueryOption) (Page[T], error) {
+	return r.FindAllByFilterWithPagination(ctx, query.Filter, query.Page, query.PerPage, query.OrderBy(), opts...)
+}
+
+// Find any records by using custom SQL Query with bound arguments, so user input never ends up in the query itself.
+//
+//	Example:
+//	FindAllUsingCustomQueryWithArgs(ctx, "name = ? AND email = ?", []interface{}{name, email}, "created_at desc")
+func (r *Repository[T]) FindAllUsingCustomQueryWithArgs(ctx context.Context, query string, args []interface{}, orderBy string, opts ...QueryOption) ([]T, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return find[T](r.query(ctx, opts).Where(query, args...).Scopes(sorted(orderBy)))
+}
+
+// Find a page of records by using custom SQL Query with bound arguments, and count all of them. The first page is 1
+func (r *Repository[T]) FindAllUsingCustomQueryWithArgsAndPagination(ctx context.Context, query string, args []interface{}, orderBy string, page, itemPerPage int, opts ...QueryOption) (Page[T], error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return findPageOf[T](r.query(ctx, opts).Where(query, args...), page, itemPerPage, orderBy, nil)
+}
+
+// Find any records by using custom SQL Query with bound arguments. Preload will get all associations in the model
+func (r *Repository[T]) FindAllUsingCustomQueryWithArgsAndPreload(ctx context.Context, query string, args []interface{}, orderBy string, opts ...QueryOption) ([]T, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return find[T](preload(r.query(ctx, opts)).Where(query, args...).Scopes(sorted(orderBy)))
+}
+
+// Find any records by using custom SQL Query with bound arguments, and preload the nested associations
+func (r *Repository[T]) FindAllUsingCustomQueryWithArgsAndNestedPreload(ctx context.Context, query string, args []interface{}, orderBy, nestedPreload string, opts ...QueryOption) ([]T, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return find[T](preload(r.query(ctx, opts), nestedPreload).Where(query, args...).Scopes(sorted(orderBy)))
+}
+
+// Find a page of records with all their associations by using custom SQL Query with bound arguments, and count all of them. The first page is 1
+func (r *Repository[T]) FindAllUsingCustomQueryWithArgsPreloadAndPagination(ctx context.Context, query string, args []interface{}, orderBy string, page, itemPerPage int, opts ...QueryOption) (Page[T], error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return findPageOf[T](r.query(ctx, opts).Where(query, args...), page, itemPerPage, orderBy, func(db *gorm.DB) *gorm.DB { return preload(db) })
+}
+
+// Find a page of records with the nested associations by using custom SQL Query with bound arguments, and count all of them. The first page is 1
+func (r *Repository[T]) FindAllUsingCustomQueryWithArgsNestedPreloadAndPagination(ctx context.Context, query string, args []interface{}, orderBy, nestedPreload string, page, itemPerPage int, opts ...QueryOption) (Page[T], error) {
+	ctx, cancel := withTimeout(ctx, r.Timeout)
+	defer cancel()
+	return findPageOf[T](r.query(ctx, opts).Where(query, args...), page, itemPerPage, orderBy, func(db *gorm.DB) *gorm.DB { return preload(db, nestedPreload) })
+}
+
+func first[T any](db *gorm.DB) (*T, error) {
+	model := new(T)
+	if err := db.First(model).Error; err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+func findPageOf[T any](db *gorm.DB, page, itemPerPage int, orderBy string, load func(*gorm.DB) *gorm.DB) (Page[T], error) {
+	items := []T{}
+	info, err := findPage(db, new(T), &items, page, itemPerPage, orderBy, load)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	return Page[T]{Items: items, PageInfo: info}, nil
+}
+
+func find[T any](db *gorm.DB) ([]T, error) {
+	models := []T{}
+	if err := db.Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return models, nil
+}
@@ -0,0 +1,184 @@
+package gobe
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type repoTeam struct {
+	ID   uint
+	Name string
+}
+
+type repoRole struct {
+	ID     uint
+	Name   string
+	TeamID uint
+	Team   repoTeam
+}
+
+type repoUser struct {
+	ID     uint
+	Name   string
+	RoleID uint
+	Role   repoRole
+}
+
+func newTestUsers(t *testing.T) Repository[repoUser] {
+	t.Helper()
+	db := newTestDB(t, &repoTeam{}, &repoRole{}, &repoUser{})
+	role := repoRole{Name: "admin", Team: repoTeam{Name: "ops"}}
+	db.Create(&role)
+	for _, name := range []string{"a", "b", "c"} {
+		db.Create(&repoUser{Name: name, RoleID: role.ID})
+	}
+	return Repository[repoUser]{Db: db}
+}
+
+func TestRepositoryCRUD(t *testing.T) {
+	repo := newTestUsers(t)
+	ctx := context.Background()
+
+	user := repoUser{Name: "d", RoleID: 1}
+	if err := repo.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+	if user.ID == 0 {
+		t.Fatal("the generated key was not read back")
+	}
+	if err := repo.UpdateBy(ctx, map[string]interface{}{"id": user.ID}, map[string]interface{}{"name": "e"}); err != nil {
+		t.Fatal(err)
+	}
+	found, err := repo.FindByWithPreload(ctx, map[string]interface{}{"id": user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Name != "e" || found.Role.Name != "admin" {
+		t.Errorf("got %+v, want e with its role", found)
+	}
+	if err := repo.DeleteBy(ctx, map[string]interface{}{"id": user.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.FindBy(ctx, map[string]interface{}{"id": user.ID}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("err = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+func TestRepositoryFind(t *testing.T) {
+	repo := newTestUsers(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		find  func() ([]repoUser, error)
+		names []string
+	}{
+		{"all", func() ([]repoUser, error) { return repo.FindAllBy(ctx, nil, "-id") }, []string{"c", "b", "a"}},
+		{"condition", func() ([]repoUser, error) {
+			return repo.FindAllBy(ctx, map[string]interface{}{"name": []string{"a", "c"}}, "id")
+		}, []string{"a", "c"}},
+		{"none", func() ([]repoUser, error) { return repo.FindAllBy(ctx, map[string]interface{}{"name": "z"}, "id") }, nil},
+		{"filter", func() ([]repoUser, error) { return repo.FindAllByFilter(ctx, Ne("name", "b"), "name desc") }, []string{"c", "a"}},
+		{"second page", func() ([]repoUser, error) {
+			page, err := repo.FindAllByWithPagination(ctx, nil, 2, 2, "id")
+			return page.Items, err
+		}, []string{"c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := tt.find()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, user := range users {
+				names = append(names, user.Name)
+			}
+			if !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("got %v, want %v", names, tt.names)
+			}
+		})
+	}
+
+	page, err := repo.FindAllByWithNestedPreloadAndPagination(ctx, nil, 1, 2, "id", "Role.Team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.TotalItems != 3 || page.TotalPages != 2 || !page.HasNext || len(page.Items) != 2 || page.Items[0].Role.Team.Name != "ops" {
+		t.Errorf("got %+v, want the first 2 of 3 users with their team", page)
+	}
+}
+
+func TestRepositoryWithTx(t *testing.T) {
+	repo := newTestUsers(t)
+	errFailed := errors.New("failed")
+
+	err := repo.Db.Transaction(func(tx *gorm.DB) error {
+		if err := repo.WithTx(tx).Create(context.Background(), &repoUser{Name: "d", RoleID: 1}); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("err = %v, want %v", err, errFailed)
+	}
+	if _, err := repo.FindBy(context.Background(), map[string]interface{}{"name": "d"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("err = %v, want the insert rolled back", err)
+	}
+}
+
+func TestCustomQueryWithArgsAndPreload(t *testing.T) {
+	repo := newTestUsers(t)
+	ctx := context.Background()
+	// Quotes in an argument are bound, not taken for SQL
+	args := []interface{}{"a", "c' OR '1'='1"}
+
+	tests := []struct {
+		name   string
+		nested bool
+		find   func() ([]repoUser, error)
+	}{
+		{"preload", false, func() ([]repoUser, error) {
+			return repo.FindAllUsingCustomQueryWithArgsAndPreload(ctx, "name IN (?, ?)", args, "id")
+		}},
+		{"nested preload", true, func() ([]repoUser, error) {
+			return repo.FindAllUsingCustomQueryWithArgsAndNestedPreload(ctx, "name IN (?, ?)", args, "id", "Role.Team")
+		}},
+		{"preload and pagination", false, func() ([]repoUser, error) {
+			page, err := repo.FindAllUsingCustomQueryWithArgsPreloadAndPagination(ctx, "name IN (?, ?)", args, "id", 1, 10)
+			return page.Items, err
+		}},
+		{"nested preload and pagination", true, func() ([]repoUser, error) {
+			page, err := repo.FindAllUsingCustomQueryWithArgsNestedPreloadAndPagination(ctx, "name IN (?, ?)", args, "id", "Role.Team", 1, 10)
+			return page.Items, err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := tt.find()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(users) != 1 || users[0].Name != "a" || users[0].Role.Name != "admin" {
+				t.Fatalf("got %+v, want only a with its role", users)
+			}
+			if tt.nested && users[0].Role.Team.Name != "ops" {
+				t.Errorf("team %q, want the nested association loaded", users[0].Role.Team.Name)
+			}
+		})
+	}
+
+	gormRepo := &GormRepository{Db: repo.Db}
+	var users []repoUser
+	info, err := gormRepo.FindPageUsingCustomQueryWithArgsAndNestedPreload(&users, "name <> ?", []interface{}{"b"}, "id", "Role.Team", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.TotalItems != 2 || len(users) != 1 || users[0].Role.Team.Name != "ops" {
+		t.Errorf("got %+v with %+v, want 2 items and a with its team", info, users)
+	}
+}
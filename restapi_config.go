@@ -4,3 +4,14 @@ type RestApiBaseConfig struct {
 	Host string `mapstructure:"host" json:"host"`
 	Port string `mapstructure:"port" json:"port"`
 }
+
+// Check the config and report all the problems at once
+func (c *RestApiBaseConfig) Validate() error {
+	return c.validate("").err()
+}
+
+func (c *RestApiBaseConfig) validate(prefix string) ValidationErrors {
+	var errs ValidationErrors
+	errs.port(prefix, "port", c.Port, false)
+	return errs
+}
@@ -0,0 +1,129 @@
+package gobe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const defaultRetryInitialDelay = 500 * time.Millisecond
+
+// Settings of the connection retry at startup. Only one attempt is made when it is left empty.
+//
+// Only dial failures are retried, a wrong password or an unsupported driver fails right away.
+type retryConfig struct {
+	// Maximum number of attempts, unlimited until the deadline when zero and a deadline is set
+	MaxAttempts int `mapstructure:"max_attempts" json:"max_attempts"`
+	// Delay before the second attempt, doubled after each attempt. Defaults to 500ms
+	InitialDelay time.Duration `mapstructure:"initial_delay" json:"initial_delay"`
+	// Upper bound of the delay between two attempts
+	MaxDelay time.Duration `mapstructure:"max_delay" json:"max_delay"`
+	// Fraction of the delay randomly added or removed, between 0 and 1, so replicas don't retry in lockstep
+	Jitter float64 `mapstructure:"jitter" json:"jitter"`
+	// Maximum total amount of time spent connecting, e.g. "2m"
+	Deadline time.Duration `mapstructure:"deadline" json:"deadline"`
+}
+
+func (r retryConfig) validate(prefix string) ValidationErrors {
+	var errs ValidationErrors
+	if r.MaxAttempts < 0 {
+		errs.add(prefix, "max_attempts", "must not be negative, got %d", r.MaxAttempts)
+	}
+	if r.InitialDelay < 0 {
+		errs.add(prefix, "initial_delay", "must not be negative, got %s", r.InitialDelay)
+	}
+	if r.MaxDelay < 0 {
+		errs.add(prefix, "max_delay", "must not be negative, got %s", r.MaxDelay)
+	} else if r.MaxDelay > 0 && r.MaxDelay < r.InitialDelay {
+		errs.add(prefix, "max_delay", "must not be less than initial_delay (%s), got %s", r.InitialDelay, r.MaxDelay)
+	}
+	if r.Jitter < 0 || r.Jitter > 1 {
+		errs.add(prefix, "jitter", "must be between 0 and 1, got %g", r.Jitter)
+	}
+	if r.Deadline < 0 {
+		errs.add(prefix, "deadline", "must not be negative, got %s", r.Deadline)
+	}
+	return errs
+}
+
+// Call fn until it succeeds, fails with something else than a dial failure, or the attempts or deadline run out
+func (r retryConfig) do(target string, fn func(ctx context.Context) error) error {
+	ctx := context.Background()
+	if r.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Deadline)
+		defer cancel()
+	}
+	maxAttempts := r.MaxAttempts
+	if maxAttempts == 0 && r.Deadline == 0 {
+		maxAttempts = 1
+	}
+	delay := r.InitialDelay
+	if delay == 0 {
+		delay = defaultRetryInitialDelay
+	}
+
+	var err error
+	attempt := 0
+	for {
+		attempt++
+		err = fn(ctx)
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("%s: connected after %d attempts", target, attempt)
+			}
+			return nil
+		}
+		if !errors.Is(err, ErrDialFailure) {
+			return err
+		}
+
+		wait := r.jitter(delay)
+		deadline, ok := ctx.Deadline()
+		if (maxAttempts > 0 && attempt >= maxAttempts) || (ok && time.Until(deadline) < wait) {
+			break
+		}
+		log.Printf("%s: attempt %d failed, retrying in %s: %s", target, attempt, wait.Round(time.Millisecond), err.Error())
+		time.Sleep(wait)
+
+		delay *= 2
+		if r.MaxDelay > 0 && delay > r.MaxDelay {
+			delay = r.MaxDelay
+		}
+	}
+	if attempt == 1 && maxAttempts == 1 {
+		return err
+	}
+	log.Printf("%s: attempt %d failed, giving up: %s", target, attempt, err.Error())
+	return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+}
+
+func (r retryConfig) jitter(delay time.Duration) time.Duration {
+	if r.Jitter == 0 {
+		return delay
+	}
+	return time.Duration(float64(delay) * (1 + r.Jitter*(2*randomFloat()-1)))
+}
+
+// Source of the retry jitter and of the replica picked, seeded once so instances started together don't draw the same
+// numbers. A rand.Rand is not safe for concurrent use, hence the mutex
+var (
+	randomMu sync.Mutex
+	random   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+func randomFloat() float64 {
+	randomMu.Lock()
+	defer randomMu.Unlock()
+	return random.Float64()
+}
+
+func randomIntn(n int) int {
+	randomMu.Lock()
+	defer randomMu.Unlock()
+	return random.Intn(n)
+}
@@ -0,0 +1,38 @@
+package gobe
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryJitter(t *testing.T) {
+	r := retryConfig{Jitter: 0.5}
+	delay := time.Second
+	seen := make(map[time.Duration]bool)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				wait := r.jitter(delay)
+				if wait < delay/2 || wait > delay*3/2 {
+					t.Errorf("wait %s, want between 500ms and 1.5s", wait)
+				}
+				mu.Lock()
+				seen[wait] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) < 100 {
+		t.Errorf("%d distinct waits out of 800, want them spread", len(seen))
+	}
+	if wait := (retryConfig{}).jitter(delay); wait != delay {
+		t.Errorf("wait %s without jitter, want %s", wait, delay)
+	}
+}
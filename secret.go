@@ -0,0 +1,133 @@
+package gobe
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const redactedSecret = "[REDACTED]"
+
+// Secret holds a sensitive configuration value such as a password.
+// It is redacted when printed or marshalled to JSON, use Value to read it.
+//
+// In the configuration file a Secret can hold a reference instead of the value itself, which is resolved
+// by ConfigLoader at load time, e.g. "file:///run/secrets/db_pass" or "env://PG_PASS". The reference can also be written
+// with the secret+ prefix, e.g. "secret+env://PG_PASS", which is the only form resolved for the fields tagged `secret:"uri"`:
+// their value is a URI itself, such as the "file:" data source name of SQLite, and would be taken for a reference.
+type Secret string
+
+// Prefix of an explicit secret reference
+const secretRefPrefix = "secret+"
+
+// Get the plain value of the secret
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redactedSecret
+}
+
+func (s Secret) GoString() string {
+	return `"` + s.String() + `"`
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + s.String() + `"`), nil
+}
+
+// SecretResolver turns a secret reference into its value. The reference is passed as written in the configuration,
+// e.g. "vault://kv/db#password", so a resolver registered for the "vault" scheme can talk to its own backend.
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretResolverFunc lets an ordinary function be used as a SecretResolver
+type SecretResolverFunc func(ctx context.Context, ref string) (string, error)
+
+func (f SecretResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// Read the secret from a file, e.g. "file:///run/secrets/db_pass". The trailing new line is removed
+var FileSecretResolver = SecretResolverFunc(func(ctx context.Context, ref string) (string, error) {
+	res, err := os.ReadFile(strings.TrimPrefix(ref, "file://"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(res), "\r\n"), nil
+})
+
+// Read the secret from an environment variable, e.g. "env://PG_PASS"
+var EnvSecretResolver = SecretResolverFunc(func(ctx context.Context, ref string) (string, error) {
+	name := strings.TrimPrefix(ref, "env://")
+	res, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return res, nil
+})
+
+// Replace every Secret field holding a reference with a registered scheme by its resolved value,
+// recording where the value was read in sources
+func resolveSecrets(ctx context.Context, v reflect.Value, prefix string, resolvers map[string]SecretResolver, sources ConfigSources) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "" || tag == "-" {
+			tag = field.Name
+		}
+		value := v.Field(i)
+		switch {
+		case field.Type == reflect.TypeOf(Secret("")):
+			ref := value.String()
+			if field.Tag.Get("secret") == "uri" && !strings.HasPrefix(ref, secretRefPrefix) {
+				continue
+			}
+			ref = strings.TrimPrefix(ref, secretRefPrefix)
+			scheme, _, ok := strings.Cut(ref, "://")
+			if !ok {
+				continue
+			}
+			resolver, ok := resolvers[scheme]
+			if !ok {
+				continue
+			}
+			res, err := resolver.Resolve(ctx, ref)
+			if err != nil {
+				return fmt.Errorf("%w: failed to resolve secret %s%s with error: %s", ErrInvalidConfig, prefix, tag, err.Error())
+			}
+			value.SetString(res)
+			sources[prefix+tag] = secretSource(scheme, ref)
+		case field.Type.Kind() == reflect.Struct:
+			if err := resolveSecrets(ctx, value, prefix+tag+".", resolvers, sources); err != nil {
+				return err
+			}
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+			for j := 0; j < value.Len(); j++ {
+				if err := resolveSecrets(ctx, value.Index(j), fmt.Sprintf("%s%s[%d].", prefix, tag, j), resolvers, sources); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Get the source of a resolved secret: the environment variable or file it was read from, or the reference for the other schemes
+func secretSource(scheme, ref string) string {
+	switch scheme {
+	case "env":
+		return "env:" + strings.TrimPrefix(ref, "env://")
+	case "file":
+		return strings.TrimPrefix(ref, "file://")
+	}
+	return ref
+}
@@ -0,0 +1,80 @@
+package gobe
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Which soft deleted records a query returns, the live ones by default
+type trashedMode int
+
+const (
+	onlyLive trashedMode = iota
+	withTrashed
+	onlyTrashed
+)
+
+// Find the soft deleted records as well as the live ones
+//
+//	Example:
+//	FindAllBy(&[]User{}, map[string]interface{}{}, "id", WithTrashed())
+func WithTrashed() QueryOption {
+	return func(o *queryOptions) {
+		o.trashed = withTrashed
+	}
+}
+
+// Find the soft deleted records only, e.g. to list the trash
+//
+//	Example:
+//	FindAllBy(&[]User{}, map[string]interface{}{}, "deleted_at desc", OnlyTrashed())
+func OnlyTrashed() QueryOption {
+	return func(o *queryOptions) {
+		o.trashed = onlyTrashed
+	}
+}
+
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+// Get the soft delete column of a model, its gorm.DeletedAt field
+func deletedAtField(db *gorm.DB, model interface{}) (*schema.Field, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	for _, field := range stmt.Schema.Fields {
+		if field.FieldType == deletedAtType && field.DBName != "" {
+			return field, nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no gorm.DeletedAt field, it cannot be soft deleted", stmt.Schema.Name)
+}
+
+// Scope keeping the soft deleted records of the model of the query
+func trashedOnly(db *gorm.DB) *gorm.DB {
+	model := db.Statement.Model
+	if model == nil {
+		model = db.Statement.Dest
+	}
+	field, err := deletedAtField(db, model)
+	if err != nil {
+		db.AddError(err)
+		return db
+	}
+	return db.Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: nil})
+}
+
+// Clear the deletion time of the soft deleted records matching the conditions
+func restore(db *gorm.DB, model interface{}, by map[string]interface{}) error {
+	field, err := deletedAtField(db, model)
+	if err != nil {
+		return err
+	}
+	return db.Unscoped().Model(model).Where(by).
+		Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: nil}).
+		Update(field.DBName, nil).Error
+}
@@ -0,0 +1,73 @@
+package gobe
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type softItem struct {
+	ID        uint
+	Name      string
+	DeletedAt gorm.DeletedAt
+}
+
+func TestSoftDelete(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(repo *GormRepository) error
+		opts  []QueryOption
+		ids   []uint
+	}{
+		{"hidden by default", nil, nil, []uint{1, 3}},
+		{"with trashed", nil, []QueryOption{WithTrashed()}, []uint{1, 2, 3}},
+		{"only trashed", nil, []QueryOption{OnlyTrashed()}, []uint{2}},
+		{"restore", func(repo *GormRepository) error {
+			return repo.Restore(&softItem{}, map[string]interface{}{"id": 2})
+		}, nil, []uint{1, 2, 3}},
+		{"force delete", func(repo *GormRepository) error {
+			return repo.ForceDelete(&softItem{}, map[string]interface{}{"id": 2})
+		}, []QueryOption{WithTrashed()}, []uint{1, 3}},
+		{"force delete of a live record", func(repo *GormRepository) error {
+			return repo.ForceDelete(&softItem{}, map[string]interface{}{"id": 1})
+		}, []QueryOption{OnlyTrashed()}, []uint{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &softItem{})
+			repo := &GormRepository{Db: db}
+			if err := db.Create(&[]softItem{{Name: "a"}, {Name: "b"}, {Name: "c"}}).Error; err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.DeleteBy(&softItem{}, map[string]interface{}{"id": 2}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.write != nil {
+				if err := tt.write(repo); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var items []softItem
+			if _, err := repo.FindAllByContext(context.Background(), &items, nil, "id", tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if len(items) != len(tt.ids) {
+				t.Fatalf("got %v, want ids %v", items, tt.ids)
+			}
+			for i, item := range items {
+				if item.ID != tt.ids[i] {
+					t.Fatalf("got %v, want ids %v", items, tt.ids)
+				}
+			}
+		})
+	}
+}
+
+func TestRestoreWithoutDeletedAt(t *testing.T) {
+	db := newTestDB(t, &txItem{})
+	repo := &GormRepository{Db: db}
+	if err := repo.Restore(&txItem{}, map[string]interface{}{"id": 1}); err == nil {
+		t.Fatal("got no error for a model without gorm.DeletedAt")
+	}
+}
@@ -0,0 +1,130 @@
+package gobe
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// UnknownColumnError is returned when a sort or a condition names a column the model doesn't have.
+// It matches ErrUnknownColumn when checked with errors.Is.
+type UnknownColumnError struct {
+	Table  string
+	Column string
+}
+
+func (e *UnknownColumnError) Error() string {
+	if e.Table == "" {
+		return fmt.Sprintf("%s %q, expected a column optionally followed by asc or desc", ErrUnknownColumn.Error(), e.Column)
+	}
+	return fmt.Sprintf("%s %q in table %s", ErrUnknownColumn.Error(), e.Column, e.Table)
+}
+
+func (e *UnknownColumnError) Is(target error) bool {
+	return target == ErrUnknownColumn
+}
+
+// Sort order of a query with one or more keys. The repositories check every column against the model before it reaches the query
+//
+//	Example:
+//	Sort{{Column: "created_at", Desc: true}, {Column: "id"}}.String() // "created_at DESC, id"
+type Sort []SortKey
+
+// Parse an order written like "created_at desc, id" or "-created_at,id". Anything other than a column,
+// e.g. an SQL expression, returns an UnknownColumnError
+func ParseSort(orderBy string) (Sort, error) {
+	var sort Sort
+	for _, item := range strings.Split(orderBy, ",") {
+		words := strings.Fields(item)
+		if len(words) == 0 {
+			continue
+		}
+		key := SortKey{Column: words[0]}
+		if strings.HasPrefix(key.Column, "-") {
+			key.Desc = true
+			key.Column = key.Column[1:]
+		}
+		if len(words) == 2 && !key.Desc && (strings.EqualFold(words[1], "asc") || strings.EqualFold(words[1], "desc")) {
+			key.Desc = strings.EqualFold(words[1], "desc")
+		} else if len(words) != 1 {
+			return nil, &UnknownColumnError{Column: strings.TrimSpace(item)}
+		}
+		if !columnPattern.MatchString(key.Column) {
+			return nil, &UnknownColumnError{Column: strings.TrimSpace(item)}
+		}
+		sort = append(sort, key)
+	}
+	return sort, nil
+}
+
+func (s Sort) String() string {
+	keys := make([]string, len(s))
+	for i, key := range s {
+		keys[i] = key.Column
+		if key.Desc {
+			keys[i] += " DESC"
+		}
+	}
+	return strings.Join(keys, ", ")
+}
+
+// Check every column of the sort belongs to the model, returning an UnknownColumnError otherwise
+func (s Sort) Validate(db *gorm.DB, model interface{}) error {
+	_, err := s.orderBy(db, model)
+	return err
+}
+
+func (s Sort) orderBy(db *gorm.DB, model interface{}) (clause.OrderBy, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return clause.OrderBy{}, err
+	}
+	var res clause.OrderBy
+	for _, key := range s {
+		field, err := lookUpColumn(stmt.Schema, key.Column)
+		if err != nil {
+			return clause.OrderBy{}, err
+		}
+		res.Columns = append(res.Columns, clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Desc: key.Desc})
+	}
+	return res, nil
+}
+
+// Find the field of a column, either its name in the database or in the struct, optionally prefixed by the table
+func lookUpColumn(s *schema.Schema, column string) (*schema.Field, error) {
+	name := column
+	if table, col, ok := strings.Cut(column, "."); ok && table == s.Table {
+		name = col
+	}
+	field := s.LookUpField(name)
+	if field == nil || field.DBName == "" {
+		return nil, &UnknownColumnError{Table: s.Table, Column: column}
+	}
+	return field, nil
+}
+
+// Scope applying the order to the query, once every column is checked against the model of the query
+func sorted(orderBy string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if strings.TrimSpace(orderBy) == "" {
+			return db
+		}
+		model := db.Statement.Model
+		if model == nil {
+			model = db.Statement.Dest
+		}
+		sort, err := ParseSort(orderBy)
+		var res clause.OrderBy
+		if err == nil {
+			res, err = sort.orderBy(db, model)
+		}
+		if err != nil {
+			db.AddError(err)
+			return db
+		}
+		return db.Clauses(res)
+	}
+}
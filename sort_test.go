@@ -0,0 +1,87 @@
+package gobe
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type sortItem struct {
+	ID    uint
+	Name  string
+	Price int
+}
+
+func TestSortedAllowedColumns(t *testing.T) {
+	db := newTestDB(t, &sortItem{})
+	db.Create(&[]sortItem{{Name: "b", Price: 2}, {Name: "a", Price: 2}, {Name: "c", Price: 1}})
+	repo := Repository[sortItem]{Db: db}
+
+	tests := []struct {
+		orderBy string
+		names   []string
+	}{
+		{"name", []string{"a", "b", "c"}},
+		{"name desc", []string{"c", "b", "a"}},
+		{"-price, name", []string{"a", "b", "c"}},
+		{"Price asc,-id", []string{"c", "a", "b"}},
+		{"sort_items.name", []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.orderBy, func(t *testing.T) {
+			items, err := repo.FindAllBy(context.Background(), nil, tt.orderBy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, item := range items {
+				names = append(names, item.Name)
+			}
+			if !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("got %v, want %v", names, tt.names)
+			}
+		})
+	}
+}
+
+func TestSortedRejectsUnknownColumns(t *testing.T) {
+	db := newTestDB(t, &sortItem{})
+	repo := Repository[sortItem]{Db: db}
+
+	tests := []struct {
+		name    string
+		orderBy string
+		column  string
+	}{
+		{"unknown column", "stock desc", "stock"},
+		{"other table", "users.name", "users.name"},
+		{"injection", "name; drop table sort_items", "name; drop table sort_items"},
+		{"expression", "length(name)", "length(name)"},
+		{"direction twice", "-name desc", "-name desc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := repo.FindAllBy(context.Background(), nil, tt.orderBy)
+			var unknown *UnknownColumnError
+			if !errors.As(err, &unknown) || !errors.Is(err, ErrUnknownColumn) {
+				t.Fatalf("err = %v, want an UnknownColumnError", err)
+			}
+			if unknown.Column != tt.column {
+				t.Errorf("column %q, want %q", unknown.Column, tt.column)
+			}
+
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			RepositoryError(c, err)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status %d, want 400", w.Code)
+			}
+		})
+	}
+}
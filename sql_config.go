@@ -1,12 +1,28 @@
 package gobe
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"net"
+	"net/url"
+	"strings"
+	"time"
 
+	clickhousedriver "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgconn"
+	_ "github.com/jackc/pgx/v4/stdlib"
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/microsoft/go-mssqldb/msdsn"
+	"gorm.io/driver/clickhouse"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/driver/sqlserver"
 	"gorm.io/gorm"
 )
 
@@ -14,20 +30,29 @@ type DBDriver string
 type DBConnection string
 
 const (
-	Mysql    DBDriver     = `mysql`
-	Postgres DBDriver     = `postgres`
-	Gorm     DBConnection = `gorm`
-	Sql      DBConnection = `sql`
+	Mysql      DBDriver     = `mysql`
+	Postgres   DBDriver     = `postgres`
+	Sqlite     DBDriver     = `sqlite`
+	Sqlserver  DBDriver     = `sqlserver`
+	Clickhouse DBDriver     = `clickhouse`
+	Gorm       DBConnection = `gorm`
+	Sql        DBConnection = `sql`
 )
 
 type GormConnector struct {
 	DataSourceName string
 	DB             *gorm.DB
+	// The query_timeout of the config, given to the repositories made by the connector
+	QueryTimeout time.Duration
+	replicas     *replicaSet
 }
 
 type SqlConnector struct {
 	DataSourceName string
+	Driver         DBDriver
 	DB             *sql.DB
+	// The query_timeout of the config, given to the repositories made by the connector
+	QueryTimeout time.Duration
 }
 
 // Base config is used to initialize connection to an SQL Database
@@ -38,64 +63,298 @@ type SqlBaseConfig struct {
 	DBHost         string              `mapstructure:"db_host" json:"db_host"`
 	DBPort         string              `mapstructure:"db_port" json:"db_port"`
 	DBUsername     string              `mapstructure:"db_username" json:"db_username"`
-	DBPassword     string              `mapstructure:"db_password" json:"db_password"`
+	DBPassword     Secret              `mapstructure:"db_password" json:"db_password"`
 	SSLMode        string              `mapstructure:"ssl_mode" json:"ssl_mode"`
-	DataSourceName string              `mapstructure:"data_source_name" json:"data_source_name"`
+	DataSourceName Secret              `mapstructure:"data_source_name" json:"data_source_name" secret:"uri"`
 	GormConfig     gormConnectorConfig `mapstructure:"gorm" json:"gorm"`
+	Pool           sqlPoolConfig       `mapstructure:"pool" json:"pool"`
+	Retry          retryConfig         `mapstructure:"retry" json:"retry"`
+	// Read replicas of the database, GormRepository reads from them and writes to the primary
+	Replicas []SqlReplicaConfig `mapstructure:"replicas" json:"replicas"`
+	// How often the replicas are checked, a replica failing the check stops receiving reads until it recovers. Defaults to 10s
+	ReplicaCheckInterval time.Duration `mapstructure:"replica_check_interval" json:"replica_check_interval"`
+	// Default timeout of the queries of the repositories made by the connectors, e.g. "5s". No timeout when empty
+	QueryTimeout time.Duration `mapstructure:"query_timeout" json:"query_timeout"`
 }
 
-// Initialize new connection using pure SQL driver
-func NewSqlConfig(config *SqlBaseConfig) SqlConnector {
-	dsn := config.DataSourceName
-	if dsn == "" {
-		switch config.Driver {
-		case Mysql:
-			dsn = getMySQLConnectionString(config)
-		case Postgres:
-			dsn = getPostgresConnectionString(config)
-		default:
-			log.Fatalln("can only initialize DB connection to MySQL or PostgreSQL")
-		}
+// Check the config and report all the problems at once
+func (c *SqlBaseConfig) Validate() error {
+	return c.validate("").err()
+}
+
+func (c *SqlBaseConfig) validate(prefix string) ValidationErrors {
+	var errs ValidationErrors
+	if c.Driver == "" {
+		errs.add(prefix, "driver", "is required")
+	} else {
+		errs.oneOf(prefix, "driver", string(c.Driver), string(Mysql), string(Postgres), string(Sqlite), string(Sqlserver), string(Clickhouse))
 	}
+	if c.Connector != "" {
+		errs.oneOf(prefix, "connector", string(c.Connector), string(Gorm), string(Sql))
+	}
+	errs = append(errs, c.Pool.validate(prefix+"pool.")...)
+	errs = append(errs, c.Retry.validate(prefix+"retry.")...)
+	for i, replica := range c.Replicas {
+		errs = append(errs, replica.validate(fmt.Sprintf("%sreplicas[%d].", prefix, i), c.Driver)...)
+	}
+	if c.ReplicaCheckInterval < 0 {
+		errs.add(prefix, "replica_check_interval", "must not be negative, got %s", c.ReplicaCheckInterval)
+	}
+	if c.QueryTimeout < 0 {
+		errs.add(prefix, "query_timeout", "must not be negative, got %s", c.QueryTimeout)
+	}
+	if c.DataSourceName != "" {
+		return errs
+	}
+	errs.required(prefix, "db_name", c.DBName)
+	if c.Driver == Sqlite {
+		return errs
+	}
+	errs.required(prefix, "db_host", c.DBHost)
+	errs.required(prefix, "db_username", c.DBUsername)
+	errs.port(prefix, "db_port", c.DBPort, c.Driver == Postgres)
+	if c.Driver == Postgres && c.SSLMode != "" {
+		errs.oneOf(prefix, "ssl_mode", c.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	}
+	return errs
+}
 
-	if config.SSLMode == "" {
-		config.SSLMode = "disable"
+// Initialize new connection using pure SQL driver. The database is pinged, so the connection is ready to use
+func NewSqlConnector(config *SqlBaseConfig) (SqlConnector, error) {
+	dsn, err := setDataSourceName(config)
+	if err != nil {
+		return SqlConnector{}, err
+	}
+
+	var sqlDb *sql.DB
+	err = config.Retry.do(string(config.Driver), func(ctx context.Context) (err error) {
+		sqlDb, err = initSqlConnection(ctx, config)
+		return err
+	})
+	if err != nil {
+		return SqlConnector{}, err
+	}
+	return SqlConnector{DataSourceName: dsn, Driver: config.Driver, DB: sqlDb, QueryTimeout: config.QueryTimeout}, nil
+}
+
+// Initialize new connection using GORM
+func NewGormConnector(config *SqlBaseConfig, table ...interface{}) (GormConnector, error) {
+	dsn, err := setDataSourceName(config)
+	if err != nil {
+		return GormConnector{}, err
+	}
+
+	var gormDb *gorm.DB
+	var replicas *replicaSet
+	err = config.Retry.do(string(config.Driver), func(ctx context.Context) (err error) {
+		gormDb, replicas, err = initGormConnection(ctx, config, table...)
+		return err
+	})
+	if err != nil {
+		return GormConnector{}, err
+	}
+	return GormConnector{DataSourceName: dsn, DB: gormDb, QueryTimeout: config.QueryTimeout, replicas: replicas}, nil
+}
+
+// Get a repository on the connection, every query is bounded by the query_timeout of the config
+func (c SqlConnector) Repository() *SqlRepository {
+	return &SqlRepository{Db: c.DB, Driver: c.Driver, Timeout: c.QueryTimeout}
+}
+
+// Get a repository on the connection, every query is bounded by the query_timeout of the config
+func (c GormConnector) Repository() *GormRepository {
+	return &GormRepository{Db: c.DB, Timeout: c.QueryTimeout}
+}
+
+// Get the live statistics of the connection pool. It returns gorm.ErrInvalidDB when the connector isn't connected
+func (c SqlConnector) Stats() (sql.DBStats, error) {
+	if c.DB == nil {
+		return sql.DBStats{}, gorm.ErrInvalidDB
 	}
+	return c.DB.Stats(), nil
+}
 
-	sqlDb := initSqlConnection(config)
-	return SqlConnector{DataSourceName: dsn, DB: sqlDb}
+// Get the live statistics of the connection pool. It returns gorm.ErrInvalidDB when the connector isn't connected
+func (c GormConnector) Stats() (sql.DBStats, error) {
+	if c.DB == nil {
+		return sql.DBStats{}, gorm.ErrInvalidDB
+	}
+	sqlDb, err := c.DB.DB()
+	if err != nil {
+		return sql.DBStats{}, err
+	}
+	return sqlDb.Stats(), nil
+}
+
+// Apply the pool settings of the config to a running connection, e.g. after the config was reloaded
+func (c SqlConnector) ConfigurePool(config *SqlBaseConfig) {
+	config.applyPool(c.DB)
+}
+
+// Apply the pool settings of the config to a running connection and its replicas, e.g. after the config was reloaded
+func (c GormConnector) ConfigurePool(config *SqlBaseConfig) error {
+	sqlDb, err := c.DB.DB()
+	if err != nil {
+		return err
+	}
+	config.applyPool(sqlDb)
+	c.replicas.each(config.applyPool)
+	return nil
+}
+
+// Stop checking the replicas and close every connection
+func (c GormConnector) Close() error {
+	c.replicas.close()
+	sqlDb, err := c.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDb.Close()
+}
+
+// Initialize new connection using pure SQL driver
+//
+// It exits the process when the connection cannot be initialized, use NewSqlConnector to handle the error instead.
+func NewSqlConfig(config *SqlBaseConfig) SqlConnector {
+	conn, err := NewSqlConnector(config)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	return conn
 }
 
 // Initialize new connection using GORM
+//
+// It exits the process when the connection cannot be initialized, use NewGormConnector to handle the error instead.
 func NewGormConfig(config *SqlBaseConfig, table ...interface{}) GormConnector {
-	dsn := config.DataSourceName
-	if dsn == "" {
+	conn, err := NewGormConnector(config, table...)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	return conn
+}
+
+// Build the data source name from the config when it is not set explicitly
+func setDataSourceName(config *SqlBaseConfig) (string, error) {
+	if config.SSLMode == "" {
+		config.SSLMode = "disable"
+	}
+
+	if config.DataSourceName == "" {
 		switch config.Driver {
 		case Mysql:
-			dsn = getMySQLConnectionString(config)
+			config.DataSourceName = Secret(getMySQLConnectionString(config))
 		case Postgres:
-			dsn = getPostgresConnectionString(config)
+			config.DataSourceName = Secret(getPostgresConnectionString(config))
+		case Sqlite:
+			config.DataSourceName = Secret(getSQLiteConnectionString(config))
+		case Sqlserver:
+			config.DataSourceName = Secret(getSqlserverConnectionString(config))
+		case Clickhouse:
+			config.DataSourceName = Secret(getClickhouseConnectionString(config))
 		default:
-			log.Fatalln("can only initialize DB connection to MySQL or PostgreSQL")
+			return "", fmt.Errorf("%w: can only initialize DB connection to MySQL, PostgreSQL, SQLite, SQL Server or ClickHouse, got %q", ErrUnsupportedDriver, config.Driver)
 		}
-		config.DataSourceName = dsn
 	}
+	return config.DataSourceName.Value(), nil
+}
 
-	if config.SSLMode == "" {
-		config.SSLMode = "disable"
+// Formatted by the driver, so the credentials and the database name are escaped
+func getMySQLConnectionString(cfg *SqlBaseConfig) string {
+	dsn := mysqldriver.NewConfig()
+	dsn.User = cfg.DBUsername
+	dsn.Passwd = cfg.DBPassword.Value()
+	dsn.Net = "tcp"
+	dsn.Addr = joinHostPort(cfg.DBHost, cfg.DBPort)
+	dsn.DBName = cfg.DBName
+	dsn.Collation = "utf8mb4_unicode_ci"
+	dsn.ParseTime = true
+	dsn.Params = map[string]string{"charset": "utf8mb4"}
+	return dsn.FormatDSN()
+}
+
+// The empty values are left out, so the driver falls back to its defaults (e.g. port 5432) instead of failing to parse them
+func getPostgresConnectionString(cfg *SqlBaseConfig) string {
+	var pairs []string
+	for _, pair := range [][2]string{
+		{"host", cfg.DBHost}, {"user", cfg.DBUsername}, {"password", cfg.DBPassword.Value()},
+		{"dbname", cfg.DBName}, {"port", cfg.DBPort}, {"sslmode", cfg.SSLMode},
+	} {
+		if pair[1] != "" {
+			pairs = append(pairs, pair[0]+"="+quotePostgresValue(pair[1]))
+		}
 	}
+	return strings.Join(pairs, " ")
+}
 
-	gormDb := initGormConnection(config, table...)
-	return GormConnector{DataSourceName: dsn, DB: gormDb}
+// Quote a value of a key=value connection string, so empty values and passwords with spaces or quotes are kept as is
+func quotePostgresValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
 
+func getSqlserverConnectionString(cfg *SqlBaseConfig) string {
+	dsn := url.URL{
+		Scheme: "sqlserver",
+		User:   urlUser(cfg),
+		Host:   joinHostPort(cfg.DBHost, cfg.DBPort),
+	}
+	if cfg.DBName != "" {
+		dsn.RawQuery = url.Values{"database": {cfg.DBName}}.Encode()
+	}
+	return dsn.String()
 }
 
-func getMySQLConnectionString(cfg *SqlBaseConfig) string {
-	return fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&collation=utf8mb4_unicode_ci&parseTime=true", cfg.DBUsername, cfg.DBPassword, cfg.DBHost, cfg.DBName)
+func getClickhouseConnectionString(cfg *SqlBaseConfig) string {
+	dsn := url.URL{
+		Scheme: "clickhouse",
+		User:   urlUser(cfg),
+		Host:   joinHostPort(cfg.DBHost, cfg.DBPort),
+		Path:   "/" + cfg.DBName,
+	}
+	return dsn.String()
 }
 
-func getPostgresConnectionString(cfg *SqlBaseConfig) string {
-	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s", cfg.DBHost, cfg.DBUsername, cfg.DBPassword, cfg.DBName, cfg.DBPort, cfg.SSLMode)
+// Escaped user info of a URL, left out when there are no credentials
+func urlUser(cfg *SqlBaseConfig) *url.Userinfo {
+	switch {
+	case cfg.DBPassword.Value() != "":
+		return url.UserPassword(cfg.DBUsername, cfg.DBPassword.Value())
+	case cfg.DBUsername != "":
+		return url.User(cfg.DBUsername)
+	}
+	return nil
+}
+
+// The port is optional for the drivers having a default one
+func joinHostPort(host, port string) string {
+	if port == "" {
+		return host
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// DBName is the path of the database file, or ":memory:" for an in-memory database
+func getSQLiteConnectionString(cfg *SqlBaseConfig) string {
+	if cfg.isSQLiteMemory() {
+		return "file::memory:?_foreign_keys=1"
+	}
+	return fmt.Sprintf("file:%s?_foreign_keys=1&_busy_timeout=5000", cfg.DBName)
+}
+
+func (c *SqlBaseConfig) isSQLiteMemory() bool {
+	return c.Driver == Sqlite && (c.DBName == ":memory:" || strings.Contains(c.DataSourceName.Value(), ":memory:") || strings.Contains(c.DataSourceName.Value(), "mode=memory"))
+}
+
+// Apply the pool settings. An in-memory SQLite database lives as long as its connection,
+// so its pool is pinned to a single connection that never expires
+func (c *SqlBaseConfig) applyPool(db *sql.DB) {
+	c.Pool.apply(db)
+	if c.isSQLiteMemory() {
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		db.SetConnMaxLifetime(0)
+		db.SetConnMaxIdleTime(0)
+	}
 }
 
 type gormConnectorConfig struct {
@@ -103,46 +362,171 @@ type gormConnectorConfig struct {
 	AutoMigrateMode bool `mapstructure:"auto_migrate" json:"auto_migrate"`
 }
 
-func initSqlConnection(baseConfig *SqlBaseConfig) *sql.DB {
-	var sqlDb *sql.DB
-	switch baseConfig.Driver {
-	case Mysql:
-		res, err := sql.Open("mysql", baseConfig.DataSourceName)
-		if err != nil {
-			log.Fatalf("failed to initialize GORM connection to MySQL with error: %s", err.Error())
+// Settings of the connection pool, zero values keep the database/sql defaults
+type sqlPoolConfig struct {
+	// Maximum number of open connections, negative means unlimited
+	MaxOpenConns int `mapstructure:"max_open_conns" json:"max_open_conns"`
+	// Maximum number of idle connections, negative means no idle connection is kept
+	MaxIdleConns int `mapstructure:"max_idle_conns" json:"max_idle_conns"`
+	// Maximum amount of time a connection may be reused, e.g. "30m"
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime" json:"conn_max_lifetime"`
+	// Maximum amount of time a connection may be idle, e.g. "5m"
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time" json:"conn_max_idle_time"`
+}
+
+func (p sqlPoolConfig) validate(prefix string) ValidationErrors {
+	var errs ValidationErrors
+	if p.MaxOpenConns > 0 && p.MaxIdleConns > p.MaxOpenConns {
+		errs.add(prefix, "max_idle_conns", "must not be greater than max_open_conns (%d), got %d", p.MaxOpenConns, p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime < 0 {
+		errs.add(prefix, "conn_max_lifetime", "must not be negative, got %s", p.ConnMaxLifetime)
+	}
+	if p.ConnMaxIdleTime < 0 {
+		errs.add(prefix, "conn_max_idle_time", "must not be negative, got %s", p.ConnMaxIdleTime)
+	}
+	return errs
+}
+
+func (p sqlPoolConfig) apply(db *sql.DB) {
+	if p.MaxOpenConns != 0 {
+		db.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns != 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime != 0 {
+		db.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+	if p.ConnMaxIdleTime != 0 {
+		db.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+	}
+}
+
+// Names of the database/sql drivers registered for each DBDriver
+var sqlDriverNames = map[DBDriver]string{
+	Mysql:      "mysql",
+	Postgres:   "pgx",
+	Sqlite:     "sqlite3",
+	Sqlserver:  "sqlserver",
+	Clickhouse: "clickhouse",
+}
+
+func initSqlConnection(ctx context.Context, baseConfig *SqlBaseConfig) (*sql.DB, error) {
+	driverName, ok := sqlDriverNames[baseConfig.Driver]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedDriver, baseConfig.Driver)
+	}
+	if err := checkDataSourceName(baseConfig.Driver, baseConfig.DataSourceName.Value()); err != nil {
+		return nil, err
+	}
+	sqlDb, err := sql.Open(driverName, baseConfig.DataSourceName.Value())
+	if err != nil {
+		return nil, fmt.Errorf("%w: data_source_name: %s", ErrInvalidConfig, err.Error())
+	}
+	// sql.Open doesn't connect, ping so a wrong host or password is reported right away
+	if err = sqlDb.PingContext(ctx); err != nil {
+		sqlDb.Close()
+		return nil, newSqlConnectionError(baseConfig.Driver, err)
+	}
+	baseConfig.applyPool(sqlDb)
+	return sqlDb, nil
+}
+
+func initGormConnection(ctx context.Context, baseConfig *SqlBaseConfig, table ...interface{}) (*gorm.DB, *replicaSet, error) {
+	// Open the pool ourselves, GORM pings it without the context of the attempt
+	sqlDb, err := initSqlConnection(ctx, baseConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	gormDb, err := gorm.Open(newGormDialector(baseConfig.Driver, baseConfig.DataSourceName.Value(), sqlDb), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		sqlDb.Close()
+		return nil, nil, newSqlConnectionError(baseConfig.Driver, err)
+	}
+
+	if baseConfig.GormConfig.DebugMode {
+		gormDb = gormDb.Debug()
+	}
+	// Migrate before the replicas are registered, so the migrator inspects the primary
+	if baseConfig.GormConfig.AutoMigrateMode {
+		if err := gormDb.AutoMigrate(table...); err != nil {
+			sqlDb.Close()
+			return nil, nil, fmt.Errorf("failed to auto migrate tables with error: %w", err)
 		}
-		sqlDb = res
+	}
+	replicas, err := registerReplicas(ctx, gormDb, baseConfig)
+	if err != nil {
+		sqlDb.Close()
+		return nil, nil, err
+	}
+	return gormDb, replicas, nil
+}
+
+// Get the GORM dialector of a driver, reusing an existing connection pool when conn is not nil
+func newGormDialector(driver DBDriver, dsn string, conn gorm.ConnPool) gorm.Dialector {
+	switch driver {
+	case Mysql:
+		return mysql.New(mysql.Config{DSN: dsn, Conn: conn})
 	case Postgres:
-		res, err := sql.Open("postgres", baseConfig.DataSourceName)
-		if err != nil {
-			log.Fatalf("failed to initialize GORM connection to Postgres with error: %s", err.Error())
-		}
-		sqlDb = res
+		return postgres.New(postgres.Config{DSN: dsn, Conn: conn})
+	case Sqlite:
+		return &sqlite.Dialector{DSN: dsn, Conn: conn}
+	case Sqlserver:
+		return sqlserver.New(sqlserver.Config{DSN: dsn, Conn: conn})
+	case Clickhouse:
+		return clickhouse.New(clickhouse.Config{DSN: dsn, Conn: conn})
 	}
-	return sqlDb
+	return nil
 }
 
-func initGormConnection(baseConfig *SqlBaseConfig, table ...interface{}) *gorm.DB {
-	var gormDb *gorm.DB
-	switch baseConfig.Driver {
+// Parse the data source name the way the driver does, so a malformed one fails right away instead of being retried as a dial failure
+func checkDataSourceName(driver DBDriver, dsn string) error {
+	var err error
+	switch driver {
 	case Mysql:
-		res, err := gorm.Open(mysql.Open(baseConfig.DataSourceName), &gorm.Config{})
-		if err != nil {
-			log.Fatalf("failed to initialize GORM connection to MySQL with error: %s", err.Error())
-		}
-		gormDb = res
+		_, err = mysqldriver.ParseDSN(dsn)
 	case Postgres:
-		res, err := gorm.Open(postgres.Open(baseConfig.DataSourceName), &gorm.Config{})
-		if err != nil {
-			log.Fatalf("failed to initialize GORM connection to Postgres with error: %s", err.Error())
-		}
-		gormDb = res
+		_, err = pgconn.ParseConfig(dsn)
+	case Sqlserver:
+		_, _, err = msdsn.Parse(dsn)
+	case Clickhouse:
+		_, err = clickhousedriver.ParseDSN(dsn)
 	}
-	if baseConfig.GormConfig.DebugMode {
-		gormDb.Debug()
+	if err != nil {
+		return fmt.Errorf("%w: data_source_name: %s", ErrInvalidConfig, err.Error())
 	}
-	if baseConfig.GormConfig.AutoMigrateMode {
-		gormDb.AutoMigrate(table...)
+	return nil
+}
+
+// Wrap a driver error into ConnectionError, telling authentication failures apart from the rest
+func newSqlConnectionError(driver DBDriver, err error) error {
+	kind := ErrDialFailure
+	var mysqlErr *mysqldriver.MySQLError
+	var pgErr *pgconn.PgError
+	var mssqlErr mssql.Error
+	var clickhouseErr *proto.Exception
+	switch {
+	case errors.As(err, &mysqlErr):
+		// ER_DBACCESS_DENIED_ERROR & ER_ACCESS_DENIED_ERROR
+		if mysqlErr.Number == 1044 || mysqlErr.Number == 1045 {
+			kind = ErrAuthFailure
+		}
+	case errors.As(err, &pgErr):
+		// invalid_authorization_specification & invalid_password
+		if pgErr.Code == "28000" || pgErr.Code == "28P01" {
+			kind = ErrAuthFailure
+		}
+	case errors.As(err, &mssqlErr):
+		// Login failed for user
+		if mssqlErr.Number == 18456 {
+			kind = ErrAuthFailure
+		}
+	case errors.As(err, &clickhouseErr):
+		// AUTHENTICATION_FAILED
+		if clickhouseErr.Code == 516 {
+			kind = ErrAuthFailure
+		}
 	}
-	return gormDb
+	return &ConnectionError{Kind: kind, Target: string(driver), Err: err}
 }
@@ -0,0 +1,215 @@
+package gobe
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const defaultReplicaCheckInterval = 10 * time.Second
+
+// SqlReplicaConfig is the connection to a read replica. Empty fields are inherited from the primary,
+// so usually only the host is set, e.g. {"db_host": "replica-1.internal"}
+type SqlReplicaConfig struct {
+	DBHost     string `mapstructure:"db_host" json:"db_host"`
+	DBPort     string `mapstructure:"db_port" json:"db_port"`
+	DBName     string `mapstructure:"db_name" json:"db_name"`
+	DBUsername string `mapstructure:"db_username" json:"db_username"`
+	DBPassword Secret `mapstructure:"db_password" json:"db_password"`
+	// Use the data source name as is instead of building it
+	DataSourceName Secret `mapstructure:"data_source_name" json:"data_source_name" secret:"uri"`
+}
+
+func (r SqlReplicaConfig) validate(prefix string, driver DBDriver) ValidationErrors {
+	var errs ValidationErrors
+	if r.DataSourceName != "" {
+		return errs
+	}
+	if driver == Sqlite {
+		errs.required(prefix, "db_name", r.DBName)
+		return errs
+	}
+	errs.required(prefix, "db_host", r.DBHost)
+	errs.port(prefix, "db_port", r.DBPort, false)
+	return errs
+}
+
+// Get the config of the replica, the fields it leaves empty are taken from the primary
+func (r SqlReplicaConfig) config(primary *SqlBaseConfig) *SqlBaseConfig {
+	cfg := &SqlBaseConfig{
+		Driver:         primary.Driver,
+		DBHost:         r.DBHost,
+		DBPort:         r.DBPort,
+		DBName:         r.DBName,
+		DBUsername:     r.DBUsername,
+		DBPassword:     r.DBPassword,
+		SSLMode:        primary.SSLMode,
+		DataSourceName: r.DataSourceName,
+		Pool:           primary.Pool,
+	}
+	if cfg.DBPort == "" {
+		cfg.DBPort = primary.DBPort
+	}
+	if cfg.DBName == "" {
+		cfg.DBName = primary.DBName
+	}
+	if cfg.DBUsername == "" {
+		cfg.DBUsername = primary.DBUsername
+	}
+	if cfg.DBPassword == "" {
+		cfg.DBPassword = primary.DBPassword
+	}
+	return cfg
+}
+
+// The replicas registered on a GORM connection and the health check watching them
+type replicaSet struct {
+	policy *replicaPolicy
+	pools  []*sql.DB
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Route the reads of the connection to the replicas of the config. Nothing is registered when there is no replica
+func registerReplicas(ctx context.Context, gormDb *gorm.DB, cfg *SqlBaseConfig) (*replicaSet, error) {
+	if len(cfg.Replicas) == 0 {
+		return nil, nil
+	}
+	primary, err := gormDb.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var dialectors []gorm.Dialector
+	for _, replica := range cfg.Replicas {
+		dsn, err := setDataSourceName(replica.config(cfg))
+		if err != nil {
+			return nil, err
+		}
+		if err = checkDataSourceName(cfg.Driver, dsn); err != nil {
+			return nil, err
+		}
+		dialectors = append(dialectors, newGormDialector(cfg.Driver, dsn, nil))
+	}
+	// The primary is the last candidate, so the policy is always consulted and reads fall back to it when every replica is down
+	dialectors = append(dialectors, newGormDialector(cfg.Driver, "", primary))
+
+	policy := &replicaPolicy{primary: primary, down: map[gorm.ConnPool]bool{}}
+	resolver := dbresolver.Register(dbresolver.Config{Replicas: dialectors, Policy: policy})
+
+	// The replicas are opened with the config of the primary, so GORM doesn't ping them
+	if err = gormDb.Use(resolver); err != nil {
+		return nil, newSqlConnectionError(cfg.Driver, err)
+	}
+
+	set := &replicaSet{policy: policy, done: make(chan struct{})}
+	resolver.Call(func(connPool gorm.ConnPool) error {
+		if db, ok := connPool.(*sql.DB); ok && db != primary {
+			set.pools = append(set.pools, db)
+		}
+		return nil
+	})
+	// Every replica must be reachable at startup, the health check only takes care of the ones going down later
+	for i, db := range set.pools {
+		if err = db.PingContext(ctx); err != nil {
+			set.close()
+			return nil, fmt.Errorf("sql replica %d: %w", i, newSqlConnectionError(cfg.Driver, err))
+		}
+	}
+	set.each(cfg.applyPool)
+
+	interval := cfg.ReplicaCheckInterval
+	if interval == 0 {
+		interval = defaultReplicaCheckInterval
+	}
+	set.check(interval)
+	go set.watch(interval)
+	return set, nil
+}
+
+// Call fn with the connection pool of every replica
+func (s *replicaSet) each(fn func(db *sql.DB)) {
+	if s == nil {
+		return
+	}
+	for _, db := range s.pools {
+		fn(db)
+	}
+}
+
+// Stop the health check and close the connections to the replicas
+func (s *replicaSet) close() {
+	if s == nil {
+		return
+	}
+	s.once.Do(func() {
+		close(s.done)
+		s.each(func(db *sql.DB) { db.Close() })
+	})
+}
+
+func (s *replicaSet) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.check(interval)
+		}
+	}
+}
+
+// Ping every replica, a replica failing the ping stops receiving reads until it answers again
+func (s *replicaSet) check(timeout time.Duration) {
+	for i, db := range s.pools {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := db.PingContext(ctx)
+		cancel()
+		if s.policy.setDown(db, err != nil) {
+			if err != nil {
+				log.Printf("sql replica %d is down, reading from the other replicas: %s", i, err.Error())
+			} else {
+				log.Printf("sql replica %d is up again", i)
+			}
+		}
+	}
+}
+
+// Pick a random healthy replica, or the primary when every replica is down
+type replicaPolicy struct {
+	primary gorm.ConnPool
+	mu      sync.RWMutex
+	down    map[gorm.ConnPool]bool
+}
+
+func (p *replicaPolicy) Resolve(connPools []gorm.ConnPool) gorm.ConnPool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var healthy []gorm.ConnPool
+	for _, connPool := range connPools {
+		if connPool != p.primary && !p.down[connPool] {
+			healthy = append(healthy, connPool)
+		}
+	}
+	if len(healthy) == 0 {
+		return p.primary
+	}
+	return healthy[randomIntn(len(healthy))]
+}
+
+// Mark a replica as down or up, returning whether its state changed
+func (p *replicaPolicy) setDown(connPool gorm.ConnPool, down bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	changed := p.down[connPool] != down
+	p.down[connPool] = down
+	return changed
+}
@@ -0,0 +1,101 @@
+package gobe
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type replicaItem struct {
+	ID   uint
+	Name string
+}
+
+func TestSqlReplicaConfigInheritsPrimary(t *testing.T) {
+	primary := &SqlBaseConfig{Driver: Postgres, DBHost: "primary", DBPort: "5432", DBName: "app", DBUsername: "app", DBPassword: "secret", SSLMode: "require"}
+	cfg := SqlReplicaConfig{DBHost: "replica-1", DBPort: "5433"}.config(primary)
+
+	if cfg.DBHost != "replica-1" || cfg.DBPort != "5433" {
+		t.Errorf("got %s:%s, want the host and port of the replica", cfg.DBHost, cfg.DBPort)
+	}
+	if cfg.Driver != Postgres || cfg.DBName != "app" || cfg.DBUsername != "app" || cfg.DBPassword != "secret" || cfg.SSLMode != "require" {
+		t.Errorf("got %+v, want the other fields of the primary", cfg)
+	}
+}
+
+func TestGormConnectorReadsFromReplicas(t *testing.T) {
+	dir := t.TempDir()
+	replicaPath := filepath.Join(dir, "replica.db")
+	replica, err := gorm.Open(sqlite.Open(replicaPath), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replica.AutoMigrate(&replicaItem{})
+	replica.Create(&replicaItem{Name: "from replica"})
+	if sqlDB, err := replica.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	cfg := SqlBaseConfig{
+		Driver:   Sqlite,
+		DBName:   filepath.Join(dir, "primary.db"),
+		Replicas: []SqlReplicaConfig{{DBName: replicaPath}},
+	}
+	cfg.GormConfig.AutoMigrateMode = true
+	conn, err := NewGormConnector(&cfg, replicaItem{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	repo := &Repository[replicaItem]{Db: conn.DB}
+	ctx := context.Background()
+	if err := repo.Create(ctx, &replicaItem{Name: "from primary"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		run  func() (*replicaItem, error)
+		want string
+	}{
+		{"default read", func() (*replicaItem, error) { return repo.FindBy(ctx, nil) }, "from replica"},
+		{"use primary", func() (*replicaItem, error) { return repo.FindBy(ctx, nil, UsePrimary()) }, "from primary"},
+		{"read in transaction", func() (item *replicaItem, err error) {
+			err = RunInTx(ctx, conn.DB, func(ctx context.Context) error {
+				item, err = repo.FindBy(ctx, nil)
+				return err
+			})
+			return item, err
+		}, "from primary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := tt.run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if item.Name != tt.want {
+				t.Errorf("got %q, want %q", item.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestGormConnectorUnreachableReplica(t *testing.T) {
+	dir := t.TempDir()
+	cfg := SqlBaseConfig{
+		Driver: Sqlite,
+		DBName: filepath.Join(dir, "primary.db"),
+		// The directory doesn't exist, so the replica cannot be opened
+		Replicas: []SqlReplicaConfig{{DBName: filepath.Join(dir, "missing", "replica.db")}},
+	}
+	_, err := NewGormConnector(&cfg)
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Fatalf("err = %v, want a ConnectionError", err)
+	}
+}
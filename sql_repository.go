@@ -0,0 +1,633 @@
+package gobe
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// Initiate a repository on a Go SQL Driver connection, e.g. SqlRepository{Db: sqlConn.DB, Driver: sqlConn.Driver}
+//
+// The queries are built from the fields of the model. The column of a field is its "db" tag, or its name in snake case.
+// The table is returned by the TableName method of the model, or is the plural of its name in snake case, like GORM does.
+//
+//	Example:
+//	type User struct {
+//		ID        int       `db:"id,pk"`          // generated by the database when left empty
+//		Name      string    `db:"name"`
+//		Nickname  *string   `db:"nickname"`       // nullable
+//		Password  string    `db:"-"`              // not a column
+//		Role      string    `db:"role,omitempty"` // not inserted when empty, so the default of the column is used
+//		CreatedAt time.Time                        // column created_at, set on insert when empty
+//	}
+type SqlRepository struct {
+	Db     *sql.DB
+	Driver DBDriver
+	// Default timeout of every query, e.g. from the query_timeout of SqlBaseConfig. No timeout when zero
+	Timeout time.Duration
+}
+
+// Create/insert a new record to the table. An empty primary key is generated by the database and set on the model
+//
+//	Example:
+//	Create(&User{Name: "XXX"})
+func (s *SqlRepository) Create(model interface{}) error {
+	return s.CreateContext(context.Background(), model)
+}
+
+// Create cancelled with the context or after the timeout of the repository
+func (s *SqlRepository) CreateContext(ctx context.Context, model interface{}) error {
+	ctx, cancel := withTimeout(ctx, s.Timeout)
+	defer cancel()
+	value := reflect.ValueOf(model)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("model must be a pointer to a struct, got %T", model)
+	}
+	value = value.Elem()
+	table, err := parseSqlTable(value.Type())
+	if err != nil {
+		return err
+	}
+	query, args, generated := s.insertQuery(table, value)
+	if generated != nil && !SupportsFeature(s.Driver, FeatureReturning) {
+		return fmt.Errorf("%w, set %s before creating the record", &UnsupportedFeatureError{Driver: s.Driver, Feature: FeatureReturning}, generated.name)
+	}
+	if generated == nil {
+		_, err = s.Db.ExecContext(ctx, query, args...)
+		return err
+	}
+
+	key := value.FieldByIndex(generated.index)
+	switch s.Driver {
+	case Postgres, Sqlite, Sqlserver:
+		return s.Db.QueryRowContext(ctx, query, args...).Scan(key.Addr().Interface())
+	default:
+		res, err := s.Db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		if key.CanInt() {
+			key.SetInt(id)
+		} else {
+			key.SetUint(uint64(id))
+		}
+		return nil
+	}
+}
+
+// Build the INSERT of a record. The primary key left empty for the database to generate it is returned,
+// and read back with RETURNING or OUTPUT on the drivers having them
+func (s *SqlRepository) insertQuery(table *sqlTable, value reflect.Value) (string, []interface{}, *sqlField) {
+	var columns, placeholders []string
+	var args []interface{}
+	var generated *sqlField
+	for i := range table.fields {
+		field := &table.fields[i]
+		fieldValue := value.FieldByIndex(field.index)
+		if field.autoTime && fieldValue.Interface().(time.Time).IsZero() {
+			fieldValue.Set(reflect.ValueOf(time.Now()))
+		}
+		if field == table.primaryKey && field.generated && fieldValue.IsZero() {
+			generated = field
+			continue
+		}
+		if field.omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+		columns = append(columns, s.quote(field.column))
+		args = append(args, fieldValue.Interface())
+		placeholders = append(placeholders, s.placeholder(len(args)))
+	}
+
+	query := "INSERT INTO " + s.quote(table.name)
+	if len(columns) > 0 || s.Driver == Mysql {
+		query += " (" + strings.Join(columns, ", ") + ")"
+	}
+	// OUTPUT comes before the values, DEFAULT VALUES included
+	if generated != nil && s.Driver == Sqlserver {
+		query += " OUTPUT INSERTED." + s.quote(generated.column)
+	}
+	if len(columns) == 0 && s.Driver != Mysql {
+		query += " DEFAULT VALUES"
+	} else {
+		query += " VALUES (" + strings.Join(placeholders, ", ") + ")"
+	}
+	if generated != nil && (s.Driver == Postgres || s.Driver == Sqlite) {
+		query += " RETURNING " + s.quote(generated.column)
+	}
+	return query, args, generated
+}
+
+// Update a value in a record.
+//
+//	Example:
+//	UpdateBy(&User{}, map[string]interface{}{"id":1}, map[string]interface{}{"name":"YYY"}) // will update a User record name with ID = 1 to "YYY"
+func (s *SqlRepository) UpdateBy(model interface{}, by map[string]interface{}, value map[string]interface{}) error {
+	return s.UpdateByContext(context.Background(), model, by, value)
+}
+
+// UpdateBy cancelled with the context or after the timeout of the repository
+func (s *SqlRepository) UpdateByContext(ctx context.Context, model interface{}, by map[string]interface{}, value map[string]interface{}) error {
+	ctx, cancel := withTimeout(ctx, s.Timeout)
+	defer cancel()
+	table, err := parseSqlTable(reflect.TypeOf(model))
+	if err != nil {
+		return err
+	}
+	if len(by) == 0 {
+		return errMissingCondition
+	}
+	if len(value) == 0 {
+		return nil
+	}
+	if field := table.columns["updated_at"]; field != nil && field.autoTime {
+		if _, ok := value["updated_at"]; !ok {
+			values := map[string]interface{}{"updated_at": time.Now()}
+			for column, v := range value {
+				values[column] = v
+			}
+			value = values
+		}
+	}
+
+	query, args, err := s.updateQuery(table, by, value)
+	if err != nil {
+		return err
+	}
+	_, err = s.Db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// Build the UPDATE of the records matching the conditions
+func (s *SqlRepository) updateQuery(table *sqlTable, by, value map[string]interface{}) (string, []interface{}, error) {
+	var sets []string
+	var args []interface{}
+	for _, column := range sortedKeys(value) {
+		if err := table.checkColumn(column); err != nil {
+			return "", nil, err
+		}
+		args = append(args, value[column])
+		sets = append(sets, s.quote(column)+" = "+s.placeholder(len(args)))
+	}
+	where, args, err := s.where(table, by, args)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := "UPDATE " + s.quote(table.name) + " SET "
+	if s.Driver == Clickhouse {
+		// ClickHouse updates through an asynchronous mutation
+		query = "ALTER TABLE " + s.quote(table.name) + " UPDATE "
+	}
+	return query + strings.Join(sets, ", ") + where, args, nil
+}
+
+// Delete a record.
+//
+//	Example:
+//	DeleteBy(&User{}, map[string]interface{}{"id":1}) // will delete a User record name with ID = 1
+func (s *SqlRepository) DeleteBy(model interface{}, by map[string]interface{}) error {
+	return s.DeleteByContext(context.Background(), model, by)
+}
+
+// DeleteBy cancelled with the context or after the timeout of the repository
+func (s *SqlRepository) DeleteByContext(ctx context.Context, model interface{}, by map[string]interface{}) error {
+	ctx, cancel := withTimeout(ctx, s.Timeout)
+	defer cancel()
+	table, err := parseSqlTable(reflect.TypeOf(model))
+	if err != nil {
+		return err
+	}
+	if len(by) == 0 {
+		return errMissingCondition
+	}
+	query, args, err := s.deleteQuery(table, by)
+	if err != nil {
+		return err
+	}
+	_, err = s.Db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// Build the DELETE of the records matching the conditions
+func (s *SqlRepository) deleteQuery(table *sqlTable, by map[string]interface{}) (string, []interface{}, error) {
+	where, args, err := s.where(table, by, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	query := "DELETE FROM " + s.quote(table.name)
+	if s.Driver == Clickhouse {
+		query = "ALTER TABLE " + s.quote(table.name) + " DELETE"
+	}
+	return query + where, args, nil
+}
+
+// Find a record by using the row name and the data. It returns sql.ErrNoRows when nothing matches
+//
+//	Example:
+//	FindBy(&User{}, map[string]interface{}{"id":1}) // will get result User with ID = 1
+func (s *SqlRepository) FindBy(model interface{}, by map[string]interface{}) (interface{}, error) {
+	return s.FindByContext(context.Background(), model, by)
+}
+
+// FindBy cancelled with the context or after the timeout of the repository
+func (s *SqlRepository) FindByContext(ctx context.Context, model interface{}, by map[string]interface{}) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, s.Timeout)
+	defer cancel()
+	value := reflect.ValueOf(model)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return model, fmt.Errorf("model must be a pointer to a struct, got %T", model)
+	}
+	table, err := parseSqlTable(value.Type())
+	if err != nil {
+		return model, err
+	}
+	where, args, err := s.where(table, by, nil)
+	if err != nil {
+		return model, err
+	}
+	query := s.selectQuery(table, where, "", 1, 0)
+	return model, s.Db.QueryRowContext(ctx, query, args...).Scan(table.scanDest(value.Elem())...)
+}
+
+// Find any records by using the row name and the data. The model is a pointer to a slice
+//
+//	Example:
+//	FindAllBy(&[]User{}, map[string]interface{}{}, "created_at desc") // will get all User
+//	FindAllBy(&[]User{}, map[string]interface{}{"name":"XXX"}, "created_at desc") // will get all User with name = "XXX"
+func (s *SqlRepository) FindAllBy(model interface{}, by map[string]interface{}, orderBy string) (interface{}, error) {
+	return s.FindAllByContext(context.Background(), model, by, orderBy)
+}
+
+// FindAllBy cancelled with the context or after the timeout of the repository
+func (s *SqlRepository) FindAllByContext(ctx context.Context, model interface{}, by map[string]interface{}, orderBy string) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, s.Timeout)
+	defer cancel()
+	return s.findAll(ctx, model, by, orderBy, 0, 0)
+}
+
+// Find any records by using the row name and the data. This will limit the result to specific number, the first page is 1.
+//
+//	Example:
+//	FindAllByWithPagination(&[]User{}, map[string]interface{}{}, 1, 10, "created_at desc") // will get the first 10 User in the column
+//	FindAllByWithPagination(&[]User{}, map[string]interface{}{}, 2, 10, "created_at desc") // will get the next 10 User in the column
+//	FindAllByWithPagination(&[]User{}, map[string]interface{}{"name":"XXX"}, 1, 10, "created_at desc") // will get the first 10 User in the column with name = "XXX"
+func (s *SqlRepository) FindAllByWithPagination(model interface{}, by map[string]interface{}, page, itemPerPage int, orderBy string) (interface{}, error) {
+	return s.FindAllByWithPaginationContext(context.Background(), model, by, page, itemPerPage, orderBy)
+}
+
+// FindAllByWithPagination cancelled with the context or after the timeout of the repository
+func (s *SqlRepository) FindAllByWithPaginationContext(ctx context.Context, model interface{}, by map[string]interface{}, page, itemPerPage int, orderBy string) (interface{}, error) {
+	ctx, cancel := withTimeout(ctx, s.Timeout)
+	defer cancel()
+	page, itemPerPage = normalizePage(page, itemPerPage)
+	return s.findAll(ctx, model, by, orderBy, itemPerPage, (page-1)*itemPerPage)
+}
+
+// Find a page of records by using the row name and the data, and count all of them. The first page is 1
+//
+//	Example:
+//	var users []User
+//	info, err := FindPageBy(&users, map[string]interface{}{"name":"XXX"}, 2, 10, "created_at desc") // will get the User 11 to 20 with name = "XXX"
+func (s *SqlRepository) FindPageBy(model interface{}, by map[string]interface{}, page, itemPerPage int, orderBy string) (PageInfo, error) {
+	return s.FindPageByContext(context.Background(), model, by, page, itemPerPage, orderBy)
+}
+
+// FindPageBy cancelled with the context or after the timeout of the repository
+func (s *SqlRepository) FindPageByContext(ctx context.Context, model interface{}, by map[string]interface{}, page, itemPerPage int, orderBy string) (PageInfo, error) {
+	ctx, cancel := withTimeout(ctx, s.Timeout)
+	defer cancel()
+	table, err := parseSqlTable(reflect.TypeOf(model))
+	if err != nil {
+		return PageInfo{}, err
+	}
+	where, args, err := s.where(table, by, nil)
+	if err != nil {
+		return PageInfo{}, err
+	}
+	var totalItems int64
+	if err := s.Db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+s.quote(table.name)+where, args...).Scan(&totalItems); err != nil {
+		return PageInfo{}, err
+	}
+	info := NewPageInfo(page, itemPerPage, totalItems)
+	if _, err := s.findAll(ctx, model, by, orderBy, info.PerPage, (info.Page-1)*info.PerPage); err != nil {
+		return PageInfo{}, err
+	}
+	return info, nil
+}
+
+func (s *SqlRepository) findAll(ctx context.Context, model interface{}, by map[string]interface{}, orderBy string, limit, offset int) (interface{}, error) {
+	value := reflect.ValueOf(model)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Slice || value.Elem().Type().Elem().Kind() != reflect.Struct {
+		return model, fmt.Errorf("model must be a pointer to a slice of structs, got %T", model)
+	}
+	slice := value.Elem()
+	table, err := parseSqlTable(slice.Type().Elem())
+	if err != nil {
+		return model, err
+	}
+	where, args, err := s.where(table, by, nil)
+	if err != nil {
+		return model, err
+	}
+	orderBy, err = s.orderBy(table, orderBy)
+	if err != nil {
+		return model, err
+	}
+
+	rows, err := s.Db.QueryContext(ctx, s.selectQuery(table, where, orderBy, limit, offset), args...)
+	if err != nil {
+		return model, err
+	}
+	defer rows.Close()
+	items := reflect.MakeSlice(slice.Type(), 0, 0)
+	for rows.Next() {
+		item := reflect.New(slice.Type().Elem()).Elem()
+		if err := rows.Scan(table.scanDest(item)...); err != nil {
+			return model, err
+		}
+		items = reflect.Append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return model, err
+	}
+	slice.Set(items)
+	return model, nil
+}
+
+var errMissingCondition = errors.New("refusing to update or delete every record, the condition is empty")
+
+func (s *SqlRepository) selectQuery(table *sqlTable, where, orderBy string, limit, offset int) string {
+	columns := make([]string, len(table.fields))
+	for i, field := range table.fields {
+		columns[i] = s.quote(field.column)
+	}
+	query := "SELECT " + strings.Join(columns, ", ") + " FROM " + s.quote(table.name) + where
+	if orderBy != "" {
+		query += " ORDER BY " + orderBy
+	}
+	if limit <= 0 {
+		return query
+	}
+	if s.Driver == Sqlserver {
+		if orderBy == "" {
+			// OFFSET FETCH needs an ORDER BY clause
+			query += " ORDER BY (SELECT NULL)"
+		}
+		return query + fmt.Sprintf(" OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", offset, limit)
+	}
+	query += fmt.Sprintf(" LIMIT %d", limit)
+	if offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", offset)
+	}
+	return query
+}
+
+// Build the WHERE clause of the conditions. A nil value matches NULL and a slice matches any of its values
+func (s *SqlRepository) where(table *sqlTable, by map[string]interface{}, args []interface{}) (string, []interface{}, error) {
+	var conditions []string
+	for _, column := range sortedKeys(by) {
+		if err := table.checkColumn(column); err != nil {
+			return "", nil, err
+		}
+		value := by[column]
+		rv := reflect.ValueOf(value)
+		switch {
+		case value == nil:
+			conditions = append(conditions, s.quote(column)+" IS NULL")
+		case (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8:
+			if rv.Len() == 0 {
+				conditions = append(conditions, "1 = 0")
+				continue
+			}
+			placeholders := make([]string, rv.Len())
+			for i := 0; i < rv.Len(); i++ {
+				args = append(args, rv.Index(i).Interface())
+				placeholders[i] = s.placeholder(len(args))
+			}
+			conditions = append(conditions, s.quote(column)+" IN ("+strings.Join(placeholders, ", ")+")")
+		default:
+			args = append(args, value)
+			conditions = append(conditions, s.quote(column)+" = "+s.placeholder(len(args)))
+		}
+	}
+	if len(conditions) == 0 {
+		return "", args, nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// Build the ORDER BY columns of a sort like "created_at desc, id", every column must belong to the table
+func (s *SqlRepository) orderBy(table *sqlTable, orderBy string) (string, error) {
+	sort, err := ParseSort(orderBy)
+	if err != nil {
+		return "", err
+	}
+	columns := make([]string, len(sort))
+	for i, key := range sort {
+		if err := table.checkColumn(key.Column); err != nil {
+			return "", err
+		}
+		columns[i] = s.quote(key.Column)
+		if key.Desc {
+			columns[i] += " DESC"
+		}
+	}
+	return strings.Join(columns, ", "), nil
+}
+
+// Get the n-th placeholder, starting at 1
+func (s *SqlRepository) placeholder(n int) string {
+	switch s.Driver {
+	case Postgres:
+		return fmt.Sprintf("$%d", n)
+	case Sqlserver:
+		return fmt.Sprintf("@p%d", n)
+	default:
+		return "?"
+	}
+}
+
+func (s *SqlRepository) quote(name string) string {
+	switch s.Driver {
+	case Mysql, Clickhouse:
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	case Sqlserver:
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	default:
+		return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// A column of a model
+type sqlField struct {
+	name      string
+	column    string
+	index     []int
+	omitEmpty bool
+	// Integer primary key generated by the database
+	generated bool
+	// CreatedAt and UpdatedAt are set to the current time
+	autoTime bool
+}
+
+// The table of a model
+type sqlTable struct {
+	name       string
+	fields     []sqlField
+	columns    map[string]*sqlField
+	primaryKey *sqlField
+}
+
+var (
+	sqlTables     sync.Map
+	sqlNaming     = schema.NamingStrategy{}
+	timeType      = reflect.TypeOf(time.Time{})
+	scannerType   = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType    = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	tableNameType = reflect.TypeOf((*interface{ TableName() string })(nil)).Elem()
+)
+
+// Get the table of a model, which is a struct or a slice of structs or a pointer to one of them
+func parseSqlTable(t reflect.Type) (*sqlTable, error) {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("model must be a struct, got %v", t)
+	}
+	if table, ok := sqlTables.Load(t); ok {
+		return table.(*sqlTable), nil
+	}
+
+	table := &sqlTable{name: sqlNaming.TableName(t.Name()), columns: map[string]*sqlField{}}
+	if reflect.PtrTo(t).Implements(tableNameType) {
+		table.name = reflect.New(t).Interface().(interface{ TableName() string }).TableName()
+	}
+	table.fields = parseSqlFields(t, nil)
+	if len(table.fields) == 0 {
+		return nil, fmt.Errorf("model %s has no column", t.Name())
+	}
+	for i := range table.fields {
+		field := &table.fields[i]
+		table.columns[field.column] = field
+	}
+	for i := range table.fields {
+		if table.fields[i].generated {
+			table.primaryKey = &table.fields[i]
+			break
+		}
+	}
+	if table.primaryKey == nil {
+		if field := table.columns["id"]; field != nil {
+			table.primaryKey = field
+			field.generated = isIntegerKind(t.FieldByIndex(field.index).Type.Kind())
+		}
+	}
+
+	res, _ := sqlTables.LoadOrStore(t, table)
+	return res.(*sqlTable), nil
+}
+
+func parseSqlFields(t reflect.Type, index []int) []sqlField {
+	var fields []sqlField
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		tag := strings.Split(structField.Tag.Get("db"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+		if structField.Anonymous && tag[0] == "" && structField.Type.Kind() == reflect.Struct && !isSqlValue(structField.Type) {
+			fields = append(fields, parseSqlFields(structField.Type, fieldIndex)...)
+			continue
+		}
+		if !isSqlValue(structField.Type) {
+			// Associations are not columns
+			continue
+		}
+
+		field := sqlField{name: structField.Name, column: tag[0], index: fieldIndex}
+		if field.column == "" {
+			field.column = sqlNaming.ColumnName("", structField.Name)
+		}
+		for _, option := range tag[1:] {
+			switch option {
+			case "omitempty":
+				field.omitEmpty = true
+			case "pk":
+				field.generated = isIntegerKind(structField.Type.Kind())
+			}
+		}
+		field.autoTime = structField.Type == timeType && (field.column == "created_at" || field.column == "updated_at")
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// Tell whether a field type can be stored in a single column
+func isSqlValue(t reflect.Type) bool {
+	if t.Implements(valuerType) || reflect.PtrTo(t).Implements(scannerType) || t == timeType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return isSqlValue(t.Elem())
+	case reflect.Struct, reflect.Map, reflect.Chan, reflect.Func, reflect.Interface:
+		return false
+	case reflect.Slice, reflect.Array:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+	return true
+}
+
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// Make sure a column of the conditions or values belongs to the table, they are written in the query as is
+func (t *sqlTable) checkColumn(column string) error {
+	if _, ok := t.columns[column]; !ok {
+		return &UnknownColumnError{Table: t.name, Column: column}
+	}
+	return nil
+}
+
+// Get the addresses of the fields of a struct in the order of the columns
+func (t *sqlTable) scanDest(value reflect.Value) []interface{} {
+	dest := make([]interface{}, len(t.fields))
+	for i, field := range t.fields {
+		dest[i] = value.FieldByIndex(field.index).Addr().Interface()
+	}
+	return dest
+}
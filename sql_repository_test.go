@@ -0,0 +1,245 @@
+package gobe
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type sqlUser struct {
+	ID        int    `db:"id,pk"`
+	Name      string `db:"name"`
+	Role      string `db:"role,omitempty"`
+	CreatedAt time.Time
+}
+
+type sqlCounter struct {
+	ID int `db:"id,pk"`
+}
+
+func newTestSqlRepository(t *testing.T) *SqlRepository {
+	t.Helper()
+	cfg := SqlBaseConfig{Driver: Sqlite, DBName: filepath.Join(t.TempDir(), "app.db")}
+	conn, err := NewSqlConnector(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.DB.Close() })
+	_, err = conn.DB.Exec(`CREATE TABLE sql_users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		role TEXT NOT NULL DEFAULT 'member',
+		created_at DATETIME
+	);
+	CREATE TABLE sql_counters (id INTEGER PRIMARY KEY AUTOINCREMENT)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn.Repository()
+}
+
+func TestSqlRepositoryCreate(t *testing.T) {
+	repo := newTestSqlRepository(t)
+
+	tests := []struct {
+		name  string
+		model interface{}
+	}{
+		{"generated key", &sqlUser{Name: "a"}},
+		{"explicit key", &sqlUser{ID: 10, Name: "b", Role: "admin"}},
+		{"default values", &sqlCounter{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := repo.Create(tt.model); err != nil {
+				t.Fatal(err)
+			}
+			if id := reflect.ValueOf(tt.model).Elem().FieldByName("ID").Int(); id == 0 {
+				t.Errorf("the generated key was not read back")
+			}
+		})
+	}
+
+	var user sqlUser
+	if _, err := repo.FindBy(&user, map[string]interface{}{"name": "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != "member" || user.CreatedAt.IsZero() {
+		t.Errorf("got %+v, want the default role and the creation time", user)
+	}
+}
+
+func TestSqlRepositoryFind(t *testing.T) {
+	repo := newTestSqlRepository(t)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		role := ""
+		if name == "a" || name == "c" {
+			role = "admin"
+		}
+		if err := repo.Create(&sqlUser{Name: name, Role: role}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		by      map[string]interface{}
+		orderBy string
+		page    int
+		names   []string
+	}{
+		{"all", nil, "id", 0, []string{"a", "b", "c", "d"}},
+		{"descending", nil, "-id", 0, []string{"d", "c", "b", "a"}},
+		{"condition", map[string]interface{}{"role": "admin"}, "name desc", 0, []string{"c", "a"}},
+		{"in", map[string]interface{}{"name": []string{"b", "d"}}, "id", 0, []string{"b", "d"}},
+		{"empty in", map[string]interface{}{"name": []string{}}, "id", 0, nil},
+		{"second page", nil, "id", 2, []string{"c", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var users []sqlUser
+			var err error
+			if tt.page > 0 {
+				_, err = repo.FindAllByWithPagination(&users, tt.by, tt.page, 2, tt.orderBy)
+			} else {
+				_, err = repo.FindAllBy(&users, tt.by, tt.orderBy)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, user := range users {
+				names = append(names, user.Name)
+			}
+			if !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("got %v, want %v", names, tt.names)
+			}
+		})
+	}
+
+	var users []sqlUser
+	info, err := repo.FindPageBy(&users, map[string]interface{}{"role": "member"}, 1, 1, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.TotalItems != 2 || len(users) != 1 || users[0].Name != "b" {
+		t.Errorf("got %+v with %v, want 2 members and b first", info, users)
+	}
+	if _, err := repo.FindBy(&sqlUser{}, map[string]interface{}{"name": "z"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("err = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestSqlRepositoryUpdateAndDelete(t *testing.T) {
+	repo := newTestSqlRepository(t)
+	a, b := sqlUser{Name: "a"}, sqlUser{Name: "b"}
+	repo.Create(&a)
+	repo.Create(&b)
+
+	if err := repo.UpdateBy(&sqlUser{}, map[string]interface{}{"id": a.ID}, map[string]interface{}{"role": "admin"}); err != nil {
+		t.Fatal(err)
+	}
+	var user sqlUser
+	repo.FindBy(&user, map[string]interface{}{"id": a.ID})
+	if user.Role != "admin" {
+		t.Errorf("role %q, want admin", user.Role)
+	}
+
+	if err := repo.DeleteBy(&sqlUser{}, map[string]interface{}{"id": a.ID}); err != nil {
+		t.Fatal(err)
+	}
+	var users []sqlUser
+	repo.FindAllBy(&users, nil, "id")
+	if len(users) != 1 || users[0].ID != b.ID {
+		t.Errorf("got %v, want only b left", users)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"update without condition", repo.UpdateBy(&sqlUser{}, nil, map[string]interface{}{"role": "x"}), errMissingCondition},
+		{"delete without condition", repo.DeleteBy(&sqlUser{}, map[string]interface{}{}), errMissingCondition},
+		{"update of an unknown column", repo.UpdateBy(&sqlUser{}, map[string]interface{}{"id": 1}, map[string]interface{}{"name; drop table sql_users": "x"}), ErrUnknownColumn},
+		{"condition on an unknown column", repo.DeleteBy(&sqlUser{}, map[string]interface{}{"nope": 1}), ErrUnknownColumn},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, tt.err, tt.want)
+		}
+	}
+}
+
+func TestSqlRepositoryQueries(t *testing.T) {
+	type queries struct {
+		insert, insertDefault, update, delete, selectPage string
+	}
+	tests := []struct {
+		driver DBDriver
+		want   queries
+	}{
+		{Mysql, queries{
+			"INSERT INTO `sql_users` (`name`, `created_at`) VALUES (?, ?)",
+			"INSERT INTO `sql_counters` () VALUES ()",
+			"UPDATE `sql_users` SET `name` = ? WHERE `id` = ?",
+			"DELETE FROM `sql_users` WHERE `id` IN (?, ?)",
+			"SELECT `id`, `name`, `role`, `created_at` FROM `sql_users` WHERE `role` = ? ORDER BY `id` DESC LIMIT 10 OFFSET 20",
+		}},
+		{Postgres, queries{
+			`INSERT INTO "sql_users" ("name", "created_at") VALUES ($1, $2) RETURNING "id"`,
+			`INSERT INTO "sql_counters" DEFAULT VALUES RETURNING "id"`,
+			`UPDATE "sql_users" SET "name" = $1 WHERE "id" = $2`,
+			`DELETE FROM "sql_users" WHERE "id" IN ($1, $2)`,
+			`SELECT "id", "name", "role", "created_at" FROM "sql_users" WHERE "role" = $1 ORDER BY "id" DESC LIMIT 10 OFFSET 20`,
+		}},
+		{Sqlite, queries{
+			`INSERT INTO "sql_users" ("name", "created_at") VALUES (?, ?) RETURNING "id"`,
+			`INSERT INTO "sql_counters" DEFAULT VALUES RETURNING "id"`,
+			`UPDATE "sql_users" SET "name" = ? WHERE "id" = ?`,
+			`DELETE FROM "sql_users" WHERE "id" IN (?, ?)`,
+			`SELECT "id", "name", "role", "created_at" FROM "sql_users" WHERE "role" = ? ORDER BY "id" DESC LIMIT 10 OFFSET 20`,
+		}},
+		{Sqlserver, queries{
+			"INSERT INTO [sql_users] ([name], [created_at]) OUTPUT INSERTED.[id] VALUES (@p1, @p2)",
+			"INSERT INTO [sql_counters] OUTPUT INSERTED.[id] DEFAULT VALUES",
+			"UPDATE [sql_users] SET [name] = @p1 WHERE [id] = @p2",
+			"DELETE FROM [sql_users] WHERE [id] IN (@p1, @p2)",
+			"SELECT [id], [name], [role], [created_at] FROM [sql_users] WHERE [role] = @p1 ORDER BY [id] DESC OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY",
+		}},
+		{Clickhouse, queries{
+			"INSERT INTO `sql_users` (`name`, `created_at`) VALUES (?, ?)",
+			"INSERT INTO `sql_counters` DEFAULT VALUES",
+			"ALTER TABLE `sql_users` UPDATE `name` = ? WHERE `id` = ?",
+			"ALTER TABLE `sql_users` DELETE WHERE `id` IN (?, ?)",
+			"SELECT `id`, `name`, `role`, `created_at` FROM `sql_users` WHERE `role` = ? ORDER BY `id` DESC LIMIT 10 OFFSET 20",
+		}},
+	}
+	users, _ := parseSqlTable(reflect.TypeOf(sqlUser{}))
+	counters, _ := parseSqlTable(reflect.TypeOf(sqlCounter{}))
+	for _, tt := range tests {
+		t.Run(string(tt.driver), func(t *testing.T) {
+			repo := &SqlRepository{Driver: tt.driver}
+			var got queries
+			got.insert, _, _ = repo.insertQuery(users, reflect.ValueOf(&sqlUser{Name: "a"}).Elem())
+			got.insertDefault, _, _ = repo.insertQuery(counters, reflect.ValueOf(&sqlCounter{}).Elem())
+			got.update, _, _ = repo.updateQuery(users, map[string]interface{}{"id": 1}, map[string]interface{}{"name": "b"})
+			got.delete, _, _ = repo.deleteQuery(users, map[string]interface{}{"id": []int{1, 2}})
+			where, _, _ := repo.where(users, map[string]interface{}{"role": "admin"}, nil)
+			orderBy, _ := repo.orderBy(users, "-id")
+			got.selectPage = repo.selectQuery(users, where, orderBy, 10, 20)
+
+			if got != tt.want {
+				gotValue, wantValue := reflect.ValueOf(got), reflect.ValueOf(tt.want)
+				for i := 0; i < gotValue.NumField(); i++ {
+					if gotValue.Field(i).String() != wantValue.Field(i).String() {
+						t.Errorf("%s:\ngot  %s\nwant %s", gotValue.Type().Field(i).Name, gotValue.Field(i), wantValue.Field(i))
+					}
+				}
+			}
+		})
+	}
+}
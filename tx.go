@@ -0,0 +1,128 @@
+package gobe
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgconn"
+	mssql "github.com/microsoft/go-mssqldb"
+	"gorm.io/gorm"
+)
+
+const defaultTxRetryDelay = 50 * time.Millisecond
+
+// Option of a transaction run by RunInTx
+type TxOption func(*txOptions)
+
+type txOptions struct {
+	retries int
+	sql     *sql.TxOptions
+}
+
+// Run the transaction again up to n times when it fails on a serialization failure or a deadlock,
+// so the function must have no side effect outside of the database
+func WithRetries(n int) TxOption {
+	return func(o *txOptions) {
+		o.retries = n
+	}
+}
+
+// Begin the transaction with an isolation level, e.g. sql.LevelSerializable
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(o *txOptions) {
+		if o.sql == nil {
+			o.sql = &sql.TxOptions{}
+		}
+		o.sql.Isolation = level
+	}
+}
+
+type txKey struct{}
+
+// Run fn in a transaction of the database, committed when fn returns nil and rolled back when it returns an error or panics.
+// The panic is raised again after the rollback.
+//
+// The context given to fn carries the transaction: every repository on the same database called with it, or a context derived from it,
+// runs its queries in the transaction, so several repositories make one unit of work.
+// Calling RunInTx again with that context nests a transaction, rolled back to a savepoint on error without aborting the outer one.
+//
+//	Example:
+//	err := RunInTx(ctx, db, func(ctx context.Context) error {
+//		if err := orderRepo.Create(ctx, &order); err != nil {
+//			return err
+//		}
+//		return productRepo.UpdateBy(ctx, map[string]interface{}{"id": order.ProductID}, map[string]interface{}{"stock": gorm.Expr("stock - ?", order.Quantity)})
+//	}, WithRetries(3))
+func RunInTx(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error, opts ...TxOption) error {
+	var options txOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if tx, ok := txFromContext(ctx, db); ok {
+		// The retries belong to the outer transaction, a failed transaction cannot be resumed from a savepoint
+		return tx.Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+	}
+	if err := requireFeature(db, FeatureTransactions); err != nil {
+		return err
+	}
+
+	delay := defaultTxRetryDelay
+	for attempt := 0; ; attempt++ {
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		}, options.sql)
+		if err == nil || attempt >= options.retries || !isRetryableTxError(err) {
+			return err
+		}
+		wait := time.Duration(float64(delay) * (0.5 + randomFloat()))
+		log.Printf("transaction attempt %d failed, retrying in %s: %s", attempt+1, wait.Round(time.Millisecond), err.Error())
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
+
+// Get the transaction of the context, if it runs on the same database
+func txFromContext(ctx context.Context, db *gorm.DB) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	if !ok || tx.Config.ConnPool != db.Config.ConnPool {
+		return nil, false
+	}
+	return tx, true
+}
+
+// Get the connection of a query, the transaction of the context when there is one on the same database
+func connection(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := txFromContext(ctx, db); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// Tell whether a transaction failed on a serialization failure or a deadlock, so it can be run again
+func isRetryableTxError(err error) bool {
+	var mysqlErr *mysqldriver.MySQLError
+	var pgErr *pgconn.PgError
+	var mssqlErr mssql.Error
+	switch {
+	case errors.As(err, &mysqlErr):
+		// ER_LOCK_DEADLOCK & ER_LOCK_WAIT_TIMEOUT
+		return mysqlErr.Number == 1213 || mysqlErr.Number == 1205
+	case errors.As(err, &pgErr):
+		// serialization_failure & deadlock_detected
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	case errors.As(err, &mssqlErr):
+		// Transaction was deadlocked
+		return mssqlErr.Number == 1205
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package gobe
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type txItem struct {
+	ID   uint
+	Name string
+}
+
+func TestRunInTx(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name  string
+		fn    func(ctx context.Context, repo *GormRepository) error
+		err   error
+		names []string
+	}{
+		{"commit", func(ctx context.Context, repo *GormRepository) error {
+			if err := repo.CreateContext(ctx, &txItem{Name: "a"}); err != nil {
+				return err
+			}
+			return repo.CreateContext(ctx, &txItem{Name: "b"})
+		}, nil, []string{"a", "b"}},
+		{"rollback on error", func(ctx context.Context, repo *GormRepository) error {
+			if err := repo.CreateContext(ctx, &txItem{Name: "a"}); err != nil {
+				return err
+			}
+			return errFailed
+		}, errFailed, nil},
+		{"nested rollback to savepoint", func(ctx context.Context, repo *GormRepository) error {
+			if err := repo.CreateContext(ctx, &txItem{Name: "a"}); err != nil {
+				return err
+			}
+			err := RunInTx(ctx, repo.Db, func(ctx context.Context) error {
+				if err := repo.CreateContext(ctx, &txItem{Name: "b"}); err != nil {
+					return err
+				}
+				return errFailed
+			})
+			if !errors.Is(err, errFailed) {
+				return err
+			}
+			return repo.CreateContext(ctx, &txItem{Name: "c"})
+		}, nil, []string{"a", "c"}},
+		{"nested error rolls back the outer transaction", func(ctx context.Context, repo *GormRepository) error {
+			if err := repo.CreateContext(ctx, &txItem{Name: "a"}); err != nil {
+				return err
+			}
+			return RunInTx(ctx, repo.Db, func(ctx context.Context) error {
+				return errFailed
+			})
+		}, errFailed, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &txItem{})
+			repo := &GormRepository{Db: db}
+			err := RunInTx(context.Background(), db, func(ctx context.Context) error {
+				return tt.fn(ctx, repo)
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			var names []string
+			db.Model(&txItem{}).Order("id").Pluck("name", &names)
+			if len(names) != len(tt.names) {
+				t.Fatalf("names %v, want %v", names, tt.names)
+			}
+			for i := range names {
+				if names[i] != tt.names[i] {
+					t.Fatalf("names %v, want %v", names, tt.names)
+				}
+			}
+		})
+	}
+}
+
+func TestRunInTxRollsBackOnPanic(t *testing.T) {
+	db := newTestDB(t, &txItem{})
+	repo := &GormRepository{Db: db}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("the panic was not raised again")
+			}
+		}()
+		RunInTx(context.Background(), db, func(ctx context.Context) error {
+			repo.CreateContext(ctx, &txItem{Name: "a"})
+			panic("boom")
+		})
+	}()
+	var count int64
+	db.Model(&txItem{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d records, want the insert rolled back", count)
+	}
+}
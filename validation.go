@@ -0,0 +1,90 @@
+package gobe
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// FieldError is a single problem found while validating a config. Path is the JSON path of the field, e.g. "sql.db_port"
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors holds every problem found while validating a config.
+// It matches ErrInvalidConfig when checked with errors.Is.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fieldErr := range e {
+		msgs[i] = fieldErr.Error()
+	}
+	return "invalid config: " + strings.Join(msgs, "; ")
+}
+
+func (e ValidationErrors) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+func (e *ValidationErrors) add(prefix, field, format string, args ...interface{}) {
+	*e = append(*e, FieldError{Path: prefix + field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e ValidationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationErrors) required(prefix, field, value string) {
+	if strings.TrimSpace(value) == "" {
+		e.add(prefix, field, "is required")
+	}
+}
+
+func (e *ValidationErrors) port(prefix, field, value string, required bool) {
+	if value == "" {
+		if required {
+			e.add(prefix, field, "is required")
+		}
+		return
+	}
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		e.add(prefix, field, "must be a number between 1 and 65535, got %q", value)
+	}
+}
+
+func (e *ValidationErrors) oneOf(prefix, field, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	e.add(prefix, field, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
+
+func (e *ValidationErrors) uri(prefix, field, value string, schemes ...string) {
+	u, err := url.Parse(value)
+	if err != nil {
+		e.add(prefix, field, "is not a valid URI: %s", err.Error())
+		return
+	}
+	if u.Host == "" {
+		e.add(prefix, field, "must contain a host")
+	}
+	for _, s := range schemes {
+		if u.Scheme == s {
+			return
+		}
+	}
+	e.add(prefix, field, "scheme must be one of %s, got %q", strings.Join(schemes, ", "), u.Scheme)
+}
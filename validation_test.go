@@ -0,0 +1,74 @@
+package gobe
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		conf  Config
+		paths []string
+	}{
+		{"empty config", Config{}, nil},
+		{"valid", Config{
+			ApiConfig:   RestApiBaseConfig{Port: "8080"},
+			SqlConfig:   SqlBaseConfig{Driver: Postgres, DBHost: "localhost", DBPort: "5432", DBUsername: "app", DBName: "app", SSLMode: "require"},
+			MongoConfig: MongoBaseConfig{URI: "mongodb+srv://cluster.example.com", DBName: "app"},
+			RedisConfig: RedisBaseConfig{Host: "localhost", Port: "6379"},
+		}, nil},
+		{"data source name skips the connection fields", Config{SqlConfig: SqlBaseConfig{Driver: Mysql, DataSourceName: "user:pass@tcp(db:3306)/app"}}, nil},
+		{"sqlite needs a file only", Config{SqlConfig: SqlBaseConfig{Driver: Sqlite}}, []string{"sql.db_name"}},
+		{"missing sql fields", Config{SqlConfig: SqlBaseConfig{Driver: Postgres}}, []string{"sql.db_name", "sql.db_host", "sql.db_username", "sql.db_port"}},
+		{"unknown driver", Config{SqlConfig: SqlBaseConfig{Driver: "oracle", DBName: "app", DBHost: "db", DBUsername: "app"}}, []string{"sql.driver"}},
+		{"invalid sql fields", Config{SqlConfig: SqlBaseConfig{
+			Driver: Postgres, Connector: "xorm", DBHost: "db", DBPort: "99999", DBUsername: "app", DBName: "app", SSLMode: "always",
+			Pool:         sqlPoolConfig{MaxOpenConns: 5, MaxIdleConns: 10},
+			Retry:        retryConfig{Jitter: 2, InitialDelay: time.Second, MaxDelay: time.Millisecond},
+			Replicas:     []SqlReplicaConfig{{DBPort: "x"}},
+			QueryTimeout: -time.Second,
+		}}, []string{
+			"sql.connector", "sql.pool.max_idle_conns", "sql.retry.max_delay", "sql.retry.jitter",
+			"sql.replicas[0].db_host", "sql.replicas[0].db_port", "sql.query_timeout", "sql.db_port", "sql.ssl_mode",
+		}},
+		{"invalid mongo uri", Config{MongoConfig: MongoBaseConfig{URI: "redis://localhost", DBName: "app"}}, []string{"mongo.uri"}},
+		{"mongo without host", Config{MongoConfig: MongoBaseConfig{DBName: "app", DBPort: "0"}}, []string{"mongo.db_host", "mongo.db_port"}},
+		{"invalid redis and api", Config{
+			ApiConfig:   RestApiBaseConfig{Port: "http"},
+			RedisConfig: RedisBaseConfig{Port: "6379", DB: -1, Pool: redisPoolConfig{PoolSize: 2, MinIdleConns: 3}},
+		}, []string{"restapi.port", "redis.host", "redis.db", "redis.pool.min_idle_conns"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.conf.Validate()
+			if tt.paths == nil {
+				if err != nil {
+					t.Fatalf("err = %v, want none", err)
+				}
+				return
+			}
+			var errs ValidationErrors
+			if !errors.As(err, &errs) || !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("err = %v, want ValidationErrors matching ErrInvalidConfig", err)
+			}
+			var paths []string
+			for _, fieldErr := range errs {
+				paths = append(paths, fieldErr.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.paths) {
+				t.Errorf("paths %v, want %v\n%v", paths, tt.paths, err)
+			}
+		})
+	}
+}
+
+func TestValidationErrorsMessage(t *testing.T) {
+	conf := SqlBaseConfig{Driver: Mysql, DBName: "app", DBHost: "db", DBUsername: "app", DBPort: "70000"}
+	want := `invalid config: db_port: must be a number between 1 and 65535, got "70000"`
+	if err := conf.Validate(); err == nil || err.Error() != want {
+		t.Errorf("got %v, want %s", err, want)
+	}
+}
@@ -0,0 +1,114 @@
+package gobe
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// StaleObjectError is returned by an update of a versioned model when the record was changed since the version the caller read.
+// It matches ErrStaleObject when checked with errors.Is.
+type StaleObjectError struct {
+	Table   string
+	Version interface{}
+}
+
+func (e *StaleObjectError) Error() string {
+	return fmt.Sprintf("%s: the record of %s was modified or deleted since version %v", ErrStaleObject.Error(), e.Table, e.Version)
+}
+
+func (e *StaleObjectError) Is(target error) bool {
+	return target == ErrStaleObject
+}
+
+// Get the version field of a model, the integer field tagged `gobe:"version"`. Nil when the model isn't versioned,
+// a field merely named Version is left alone since it can be a release or schema number
+func versionField(s *schema.Schema) *schema.Field {
+	for _, field := range s.Fields {
+		if field.DBName != "" && isIntegerKind(field.FieldType.Kind()) && field.Tag.Get("gobe") == "version" {
+			return field
+		}
+	}
+	return nil
+}
+
+// Update the records matching the conditions. The version of a versioned model is incremented by every update,
+// and checked when the values, the conditions or the model hold the version the caller read
+func updateBy(db *gorm.DB, model interface{}, by, value map[string]interface{}) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	field := versionField(stmt.Schema)
+	if field == nil {
+		return db.Model(model).Where(by).Updates(value).Error
+	}
+
+	updates := make(map[string]interface{}, len(value)+1)
+	var expected interface{}
+	for key, v := range value {
+		if key == field.DBName || key == field.Name {
+			expected = v
+			continue
+		}
+		updates[key] = v
+	}
+	if expected == nil {
+		if v, ok := by[field.DBName]; ok {
+			expected = v
+		} else if v, ok := by[field.Name]; ok {
+			expected = v
+		}
+	}
+	item := reflect.Indirect(reflect.ValueOf(model))
+	if expected == nil && item.Kind() == reflect.Struct {
+		if v, zero := field.ValueOf(db.Statement.Context, item); !zero {
+			expected = v
+		}
+	}
+	if next, ok := nextVersion(expected); ok {
+		expected = next - 1
+	}
+	column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+	updates[field.DBName] = gorm.Expr("? + 1", column)
+
+	query := db.Model(model).Where(by)
+	if expected == nil {
+		return query.Updates(updates).Error
+	}
+	if err := requireFeature(db, FeatureRowsAffected); err != nil {
+		return err
+	}
+	res := query.Where(clause.Eq{Column: column, Value: expected}).Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return &StaleObjectError{Table: stmt.Schema.Table, Version: expected}
+	}
+	// Keep the model in sync, so it can be updated again
+	if next, ok := nextVersion(expected); ok && item.Kind() == reflect.Struct && item.CanAddr() {
+		return field.Set(db.Statement.Context, item, next)
+	}
+	return nil
+}
+
+func nextVersion(version interface{}) (int64, bool) {
+	if version == nil {
+		return 0, false
+	}
+	v := reflect.ValueOf(version)
+	switch {
+	case v.CanInt():
+		return v.Int() + 1, true
+	case v.CanUint():
+		return int64(v.Uint()) + 1, true
+	case v.CanFloat():
+		// A version decoded from a JSON body
+		return int64(v.Float()) + 1, true
+	}
+	return 0, false
+}
@@ -0,0 +1,99 @@
+package gobe
+
+import (
+	"errors"
+	"testing"
+)
+
+type versionItem struct {
+	ID      uint
+	Name    string
+	Version int `gobe:"version"`
+}
+
+// Version is a release number, not a lock
+type releaseItem struct {
+	ID      uint
+	Name    string
+	Version int
+}
+
+func TestUpdateByVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   *versionItem
+		by      map[string]interface{}
+		value   map[string]interface{}
+		err     error
+		version int
+	}{
+		{"unchecked", &versionItem{}, map[string]interface{}{"id": 1}, map[string]interface{}{"name": "b"}, nil, 3},
+		{"version in the values", &versionItem{}, map[string]interface{}{"id": 1}, map[string]interface{}{"name": "b", "version": 2}, nil, 3},
+		{"version in the conditions", &versionItem{}, map[string]interface{}{"id": 1, "version": 2}, map[string]interface{}{"name": "b"}, nil, 3},
+		{"version of the model", &versionItem{ID: 1, Version: 2}, map[string]interface{}{"id": 1}, map[string]interface{}{"name": "b"}, nil, 3},
+		{"version decoded from JSON", &versionItem{}, map[string]interface{}{"id": 1}, map[string]interface{}{"name": "b", "version": float64(2)}, nil, 3},
+		{"stale version", &versionItem{}, map[string]interface{}{"id": 1}, map[string]interface{}{"name": "b", "version": 1}, ErrStaleObject, 2},
+		{"stale model", &versionItem{ID: 1, Version: 1}, map[string]interface{}{"id": 1}, map[string]interface{}{"name": "b"}, ErrStaleObject, 2},
+		{"deleted record", &versionItem{}, map[string]interface{}{"id": 9}, map[string]interface{}{"name": "b", "version": 2}, ErrStaleObject, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &versionItem{})
+			if err := db.Create(&versionItem{Name: "a", Version: 2}).Error; err != nil {
+				t.Fatal(err)
+			}
+			repo := &GormRepository{Db: db}
+			err := repo.UpdateBy(tt.model, tt.by, tt.value)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			var item versionItem
+			db.First(&item, 1)
+			if item.Version != tt.version {
+				t.Errorf("version %d, want %d", item.Version, tt.version)
+			}
+		})
+	}
+}
+
+func TestUpdateByKeepsModelVersion(t *testing.T) {
+	db := newTestDB(t, &versionItem{})
+	item := versionItem{Name: "a", Version: 1}
+	db.Create(&item)
+	repo := &GormRepository{Db: db}
+	for _, name := range []string{"b", "c"} {
+		if err := repo.UpdateBy(&item, map[string]interface{}{"id": item.ID}, map[string]interface{}{"name": name}); err != nil {
+			t.Fatalf("update to %s: %v", name, err)
+		}
+	}
+	if item.Version != 3 {
+		t.Errorf("model version %d, want 3", item.Version)
+	}
+}
+
+func TestUpdateByUntaggedVersion(t *testing.T) {
+	db := newTestDB(t, &releaseItem{})
+	db.Create(&releaseItem{Name: "a", Version: 7})
+	repo := &GormRepository{Db: db}
+
+	tests := []struct {
+		name    string
+		value   map[string]interface{}
+		version int
+	}{
+		{"not incremented", map[string]interface{}{"name": "b"}, 7},
+		{"set like any column", map[string]interface{}{"version": 8}, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := repo.UpdateBy(&releaseItem{}, map[string]interface{}{"id": 1}, tt.value); err != nil {
+				t.Fatal(err)
+			}
+			var item releaseItem
+			db.First(&item, 1)
+			if item.Version != tt.version {
+				t.Errorf("version %d, want %d", item.Version, tt.version)
+			}
+		})
+	}
+}